@@ -0,0 +1,5 @@
+data "softserve_pubkey" "current" {}
+
+output "authenticated_as" {
+  value = data.softserve_pubkey.current.username
+}
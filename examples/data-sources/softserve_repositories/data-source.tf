@@ -0,0 +1,8 @@
+# Every repository nested under team/
+data "softserve_repositories" "team" {
+  prefix = "team/"
+}
+
+output "team_repositories" {
+  value = data.softserve_repositories.team.names
+}
@@ -0,0 +1,7 @@
+data "softserve_repository" "example" {
+  name = "my-project"
+}
+
+output "my_project_access" {
+  value = data.softserve_repository.example.access
+}
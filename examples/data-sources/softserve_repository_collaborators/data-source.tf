@@ -0,0 +1,12 @@
+data "softserve_repository_collaborators" "existing" {
+  repository = "my-project"
+}
+
+# Manage the collaborators of an imported repository as they are today
+resource "softserve_repository_collaborator" "existing" {
+  for_each = data.softserve_repository_collaborators.existing.collaborators
+
+  repository   = "my-project"
+  username     = each.key
+  access_level = each.value
+}
@@ -0,0 +1,5 @@
+data "softserve_settings" "current" {}
+
+output "anon_access" {
+  value = data.softserve_settings.current.anon_access
+}
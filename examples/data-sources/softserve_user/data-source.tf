@@ -0,0 +1,7 @@
+data "softserve_user" "alice" {
+  username = "alice"
+}
+
+output "alice_created_at" {
+  value = data.softserve_user.alice.created_at
+}
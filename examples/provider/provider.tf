@@ -12,5 +12,6 @@ provider "softserve" {
   username = "admin"
   # private_key_path = "~/.ssh/id_ed25519"
   # identity_file    = "~/.ssh/id_ed25519.pub"
+  # known_hosts_file = "~/.ssh/known_hosts"
   use_agent = true
 }
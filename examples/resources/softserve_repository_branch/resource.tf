@@ -0,0 +1,5 @@
+resource "softserve_repository_branch" "develop" {
+  repository = softserve_repository.example.name
+  name       = "develop"
+  from       = "main"
+}
@@ -0,0 +1,8 @@
+resource "softserve_repository_collaborators" "example" {
+  repository = softserve_repository.example.name
+
+  collaborators = {
+    (softserve_user.example.username) = "read-write"
+    "reviewer"                        = "read-only"
+  }
+}
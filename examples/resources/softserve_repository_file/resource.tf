@@ -0,0 +1,7 @@
+resource "softserve_repository_file" "readme" {
+  repository     = softserve_repository.example.name
+  branch         = "main"
+  path           = "README.md"
+  content        = "# My Project\n"
+  commit_message = "Add README"
+}
@@ -0,0 +1,796 @@
+package datasource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+
+	"github.com/ssoriche/terraform-provider-soft-serve/internal/ssh"
+	"github.com/ssoriche/terraform-provider-soft-serve/internal/sshtest"
+)
+
+// --- Test Helpers ---
+
+// newTestClient returns an SSH client connected to an in-process server that
+// answers commands with handler.
+func newTestClient(t *testing.T, handler sshtest.Handler) (*ssh.Client, *sshtest.Server) {
+	t.Helper()
+	srv := sshtest.NewServer(t, handler)
+	client, err := ssh.NewClient(ssh.ClientConfig{
+		Host:       srv.Host(),
+		Port:       srv.Port(),
+		Username:   "admin",
+		PrivateKey: sshtest.PrivateKey(t),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+	return client, srv
+}
+
+// dataSourceSchema returns the schema of d.
+func dataSourceSchema(t *testing.T, d datasource.DataSource) schema.Schema {
+	t.Helper()
+	resp := &datasource.SchemaResponse{}
+	d.Schema(context.Background(), datasource.SchemaRequest{}, resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected schema errors: %s", resp.Diagnostics)
+	}
+	return resp.Schema
+}
+
+// readDataSource runs d.Read with the given config attribute values and
+// returns the resulting state, failing the test on any error.
+func readDataSource(t *testing.T, d datasource.DataSource, config map[string]tftypes.Value) tfsdk.State {
+	t.Helper()
+	resp := runRead(t, d, config)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Read() errors: %s", resp.Diagnostics)
+	}
+	return resp.State
+}
+
+// runRead runs d.Read with the given config attribute values and returns the
+// response as is.
+func runRead(t *testing.T, d datasource.DataSource, config map[string]tftypes.Value) *datasource.ReadResponse {
+	t.Helper()
+	ctx := context.Background()
+	s := dataSourceSchema(t, d)
+	objType := s.Type().TerraformType(ctx).(tftypes.Object)
+
+	values := make(map[string]tftypes.Value, len(objType.AttributeTypes))
+	for name, typ := range objType.AttributeTypes {
+		if v, ok := config[name]; ok {
+			values[name] = v
+		} else {
+			values[name] = tftypes.NewValue(typ, nil)
+		}
+	}
+
+	resp := &datasource.ReadResponse{
+		State: tfsdk.State{Schema: s, Raw: tftypes.NewValue(objType, nil)},
+	}
+	d.Read(ctx, datasource.ReadRequest{
+		Config: tfsdk.Config{Schema: s, Raw: tftypes.NewValue(objType, values)},
+	}, resp)
+	return resp
+}
+
+// --- Repository Data Source Tests ---
+
+func TestRepositoryDataSourceMetadata(t *testing.T) {
+	d := NewRepositoryDataSource()
+	resp := &datasource.MetadataResponse{}
+
+	d.Metadata(context.Background(), datasource.MetadataRequest{ProviderTypeName: "softserve"}, resp)
+
+	if resp.TypeName != "softserve_repository" {
+		t.Errorf("TypeName = %q, want %q", resp.TypeName, "softserve_repository")
+	}
+}
+
+func TestRepositoryDataSourceSchema(t *testing.T) {
+	s := dataSourceSchema(t, NewRepositoryDataSource())
+
+	expectedAttrs := []string{"id", "name", "description", "project_name", "private", "hidden", "mirror", "upstream_url", "owner", "access", "is_empty", "collaborator_count", "collaborators", "ssh_clone_url", "http_clone_url", "created_at", "updated_at", "last_push", "size_bytes"}
+	for _, attr := range expectedAttrs {
+		if _, ok := s.Attributes[attr]; !ok {
+			t.Errorf("missing expected attribute %q", attr)
+		}
+	}
+
+	if len(s.Attributes) != len(expectedAttrs) {
+		t.Errorf("got %d attributes, want %d", len(s.Attributes), len(expectedAttrs))
+	}
+
+	if !s.Attributes["name"].IsRequired() {
+		t.Error("name attribute should be required")
+	}
+	for name, attr := range s.Attributes {
+		if name != "name" && !attr.IsComputed() {
+			t.Errorf("%q should be computed", name)
+		}
+	}
+}
+
+func TestRepositoryDataSourceConfigure_NilProviderData(t *testing.T) {
+	d := &RepositoryDataSource{}
+	resp := &datasource.ConfigureResponse{}
+
+	d.Configure(context.Background(), datasource.ConfigureRequest{
+		ProviderData: nil,
+	}, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Errorf("unexpected error with nil provider data: %s", resp.Diagnostics)
+	}
+}
+
+func TestRepositoryDataSourceConfigure_WrongType(t *testing.T) {
+	d := &RepositoryDataSource{}
+	resp := &datasource.ConfigureResponse{}
+
+	d.Configure(context.Background(), datasource.ConfigureRequest{
+		ProviderData: "wrong-type",
+	}, resp)
+
+	if !resp.Diagnostics.HasError() {
+		t.Error("expected error with wrong provider data type")
+	}
+}
+
+func TestRepositoryDataSourceRead(t *testing.T) {
+	tests := []struct {
+		name         string
+		output       string
+		wantAccess   string
+		wantEmpty    bool
+		wantUpstream string
+		wantCreated  string // empty for null
+		wantUpdated  string
+		wantPush     string
+		wantSize     int64 // 0 for null
+	}{
+		{
+			name:       "server reports access",
+			output:     "Repository: myrepo\nPrivate: true\nHidden: false\nMirror: false\nOwner: admin\nAccess: read-write\nDefault Branch: main\nBranches:\n  - main",
+			wantAccess: "read-write",
+		},
+		{
+			name:       "older server without access",
+			output:     "Repository: myrepo\nPrivate: true\nHidden: false\nMirror: false\nOwner: admin\nBranches:\n  - main",
+			wantAccess: "",
+		},
+		{
+			name:      "no commits yet",
+			output:    "Repository: myrepo\nPrivate: true\nHidden: false\nMirror: false\nOwner: admin\nBranches:",
+			wantEmpty: true,
+		},
+		{
+			name:         "mirror",
+			output:       "Repository: myrepo\nPrivate: true\nHidden: false\nMirror: true\nMirror URL: https://example.com/upstream.git\nOwner: admin\nBranches:\n  - main",
+			wantUpstream: "https://example.com/upstream.git",
+		},
+		{
+			name:        "with timestamps",
+			output:      "Repository: myrepo\nPrivate: true\nHidden: false\nMirror: false\nOwner: admin\nBranches:\n  - main\nCreated At: 2024-03-01 09:30:00 +0000 UTC\nUpdated At: 2024-06-15T12:00:00Z",
+			wantCreated: "2024-03-01T09:30:00Z",
+			wantUpdated: "2024-06-15T12:00:00Z",
+		},
+		{
+			name:     "with last push",
+			output:   "Repository: myrepo\nPrivate: true\nHidden: false\nMirror: false\nOwner: admin\nBranches:\n  - main\nLast Push: 2024-06-15 11:59:00 +0000 UTC",
+			wantPush: "2024-06-15T11:59:00Z",
+		},
+		{
+			name:     "with size",
+			output:   "Repository: myrepo\nPrivate: true\nHidden: false\nMirror: false\nOwner: admin\nBranches:\n  - main\nSize: 1.5 MiB",
+			wantSize: 1572864,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, srv := newTestClient(t, func(cmd string) (string, error) {
+				if cmd == "repo collab list myrepo" {
+					return "alice read-write\nbob read-only\ncarol", nil
+				}
+				return tt.output, nil
+			})
+			d := &RepositoryDataSource{client: client}
+
+			state := readDataSource(t, d, map[string]tftypes.Value{
+				"name": tftypes.NewValue(tftypes.String, "myrepo"),
+			})
+
+			var model RepositoryDataSourceModel
+			if diags := state.Get(context.Background(), &model); diags.HasError() {
+				t.Fatalf("reading state: %s", diags)
+			}
+			want := []string{"repo info myrepo", "repo collab list myrepo"}
+			if tt.wantAccess == "" {
+				// Without a reported access level, it's worked out from the identity
+				want = append(want, "info")
+			}
+			if got := srv.Commands(); !slices.Equal(got, want) {
+				t.Errorf("commands = %q, want %q", got, want)
+			}
+			if model.CollaboratorCount.ValueInt64() != 3 {
+				t.Errorf("collaborator_count = %v, want 3", model.CollaboratorCount)
+			}
+			var collaborators map[string]string
+			model.Collaborators.ElementsAs(context.Background(), &collaborators, false)
+			if want := map[string]string{"alice": "read-write", "bob": "read-only", "carol": "read-write"}; !maps.Equal(collaborators, want) {
+				t.Errorf("collaborators = %v, want %v", collaborators, want)
+			}
+			if model.Access.ValueString() != tt.wantAccess {
+				t.Errorf("access = %q, want %q", model.Access.ValueString(), tt.wantAccess)
+			}
+			if !model.Private.ValueBool() {
+				t.Error("private = false, want true")
+			}
+			if model.IsEmpty.ValueBool() != tt.wantEmpty {
+				t.Errorf("is_empty = %v, want %v", model.IsEmpty.ValueBool(), tt.wantEmpty)
+			}
+			if tt.wantUpstream == "" && !model.UpstreamURL.IsNull() {
+				t.Errorf("upstream_url = %v, want null", model.UpstreamURL)
+			} else if model.UpstreamURL.ValueString() != tt.wantUpstream {
+				t.Errorf("upstream_url = %q, want %q", model.UpstreamURL.ValueString(), tt.wantUpstream)
+			}
+			for attr, tc := range map[string]struct {
+				got  types.String
+				want string
+			}{
+				"created_at": {model.CreatedAt, tt.wantCreated},
+				"updated_at": {model.UpdatedAt, tt.wantUpdated},
+				"last_push":  {model.LastPush, tt.wantPush},
+			} {
+				if tc.want == "" {
+					if !tc.got.IsNull() {
+						t.Errorf("%s = %v, want null", attr, tc.got)
+					}
+				} else if tc.got.ValueString() != tc.want {
+					t.Errorf("%s = %q, want %q", attr, tc.got.ValueString(), tc.want)
+				}
+			}
+			if tt.wantSize == 0 && !model.SizeBytes.IsNull() {
+				t.Errorf("size_bytes = %v, want null", model.SizeBytes)
+			} else if model.SizeBytes.ValueInt64() != tt.wantSize {
+				t.Errorf("size_bytes = %d, want %d", model.SizeBytes.ValueInt64(), tt.wantSize)
+			}
+			if model.Owner.ValueString() != "admin" {
+				t.Errorf("owner = %q, want %q", model.Owner.ValueString(), "admin")
+			}
+			if want := client.SSHCloneURL("myrepo"); model.SSHCloneURL.ValueString() != want {
+				t.Errorf("ssh_clone_url = %q, want %q", model.SSHCloneURL.ValueString(), want)
+			}
+			if !model.HTTPCloneURL.IsNull() {
+				t.Errorf("http_clone_url = %v, want null without an HTTP base URL", model.HTTPCloneURL)
+			}
+		})
+	}
+}
+
+func TestRepositoryDataSourceRead_DerivedAccess(t *testing.T) {
+	tests := []struct {
+		name     string
+		identity string
+		want     string
+	}{
+		{"admin", "Username: root\nAdmin: true", "admin-access"},
+		{"owner", "Username: owner\nAdmin: false", "admin-access"},
+		{"collaborator", "Username: alice\nAdmin: false", "read-only"},
+		{"collaborator without level", "Username: bob\nAdmin: false", "read-write"},
+		{"no access", "Username: carol\nAdmin: false", ""},
+		{"unknown identity", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, _ := newTestClient(t, func(cmd string) (string, error) {
+				switch cmd {
+				case "info":
+					return tt.identity, nil
+				case "repo collab list myrepo":
+					return "alice read-only\nbob", nil
+				}
+				return "Repository: myrepo\nPrivate: true\nOwner: owner\nBranches:", nil
+			})
+			d := &RepositoryDataSource{client: client}
+
+			state := readDataSource(t, d, map[string]tftypes.Value{
+				"name": tftypes.NewValue(tftypes.String, "myrepo"),
+			})
+
+			var model RepositoryDataSourceModel
+			if diags := state.Get(context.Background(), &model); diags.HasError() {
+				t.Fatalf("reading state: %s", diags)
+			}
+			if got := model.Access.ValueString(); got != tt.want {
+				t.Errorf("access = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRepositoryDataSourceRead_NameCase(t *testing.T) {
+	client, _ := newTestClient(t, func(cmd string) (string, error) {
+		if cmd == "repo info MyRepo" {
+			return "Repository: myrepo\nPrivate: true\nOwner: admin\nAccess: read-write\nBranches:", nil
+		}
+		return "", nil
+	})
+	d := &RepositoryDataSource{client: client}
+
+	// As for the resource, id is the server's name and name is as configured
+	state := readDataSource(t, d, map[string]tftypes.Value{
+		"name": tftypes.NewValue(tftypes.String, "MyRepo"),
+	})
+
+	var model RepositoryDataSourceModel
+	if diags := state.Get(context.Background(), &model); diags.HasError() {
+		t.Fatalf("reading state: %s", diags)
+	}
+	if model.ID.ValueString() != "myrepo" || model.Name.ValueString() != "MyRepo" {
+		t.Errorf("id = %q, name = %q, want %q and %q", model.ID.ValueString(), model.Name.ValueString(), "myrepo", "MyRepo")
+	}
+}
+
+func TestRepositoryDataSourceRead_CollaboratorsUnauthorized(t *testing.T) {
+	client, _ := newTestClient(t, func(cmd string) (string, error) {
+		if cmd == "repo collab list myrepo" {
+			return "", errors.New("unauthorized")
+		}
+		return "Repository: myrepo\nPrivate: false\nOwner: admin\nAccess: read-only", nil
+	})
+	d := &RepositoryDataSource{client: client}
+
+	state := readDataSource(t, d, map[string]tftypes.Value{
+		"name": tftypes.NewValue(tftypes.String, "myrepo"),
+	})
+
+	var model RepositoryDataSourceModel
+	if diags := state.Get(context.Background(), &model); diags.HasError() {
+		t.Fatalf("reading state: %s", diags)
+	}
+	if !model.CollaboratorCount.IsNull() {
+		t.Errorf("collaborator_count = %v, want null when listing is refused", model.CollaboratorCount)
+	}
+	if !model.Collaborators.IsNull() {
+		t.Errorf("collaborators = %v, want null when listing is refused", model.Collaborators)
+	}
+}
+
+// --- Settings Data Source Tests ---
+
+func TestSettingsDataSourceMetadata(t *testing.T) {
+	d := NewSettingsDataSource()
+	resp := &datasource.MetadataResponse{}
+
+	d.Metadata(context.Background(), datasource.MetadataRequest{ProviderTypeName: "softserve"}, resp)
+
+	if resp.TypeName != "softserve_settings" {
+		t.Errorf("TypeName = %q, want %q", resp.TypeName, "softserve_settings")
+	}
+}
+
+func TestSettingsDataSourceSchema(t *testing.T) {
+	s := dataSourceSchema(t, NewSettingsDataSource())
+
+	for _, attr := range []string{"id", "allow_keyless", "anon_access"} {
+		a, ok := s.Attributes[attr]
+		if !ok {
+			t.Errorf("missing attribute %q", attr)
+			continue
+		}
+		if !a.IsComputed() || a.IsOptional() || a.IsRequired() {
+			t.Errorf("attribute %q should be computed only", attr)
+		}
+	}
+}
+
+func TestSettingsDataSourceRead(t *testing.T) {
+	client, srv := newTestClient(t, func(cmd string) (string, error) {
+		if cmd == "settings allow-keyless" {
+			return "true\n", nil
+		}
+		return "read-only\n", nil
+	})
+	d := &SettingsDataSource{client: client}
+
+	state := readDataSource(t, d, nil)
+
+	var model SettingsDataSourceModel
+	if diags := state.Get(context.Background(), &model); diags.HasError() {
+		t.Fatalf("reading state: %s", diags)
+	}
+	if !model.AllowKeyless.ValueBool() {
+		t.Error("allow_keyless = false, want true")
+	}
+	if model.AnonAccess.ValueString() != "read-only" {
+		t.Errorf("anon_access = %q, want %q", model.AnonAccess.ValueString(), "read-only")
+	}
+	if got := srv.Commands(); len(got) != 2 {
+		t.Errorf("commands = %q, want 2 settings reads", got)
+	}
+}
+
+func TestSettingsDataSourceRead_NotAdmin(t *testing.T) {
+	client, _ := newTestClient(t, func(string) (string, error) {
+		return "", errors.New("unauthorized")
+	})
+	d := &SettingsDataSource{client: client}
+
+	resp := runRead(t, d, nil)
+
+	if !resp.Diagnostics.HasError() {
+		t.Fatal("expected an error when the user is not an admin")
+	}
+	if got := resp.Diagnostics.Errors()[0].Summary(); got != "Admin required" {
+		t.Errorf("summary = %q, want %q", got, "Admin required")
+	}
+}
+
+// --- Raw Settings Data Source Tests ---
+
+func TestRawSettingsDataSourceRead(t *testing.T) {
+	client, srv := newTestClient(t, func(cmd string) (string, error) {
+		switch cmd {
+		case "settings allow-keyless":
+			return "true\n", nil
+		case "settings anon-access":
+			return "read-only\n", nil
+		}
+		return "", errors.New("unknown command")
+	})
+	d := &RawSettingsDataSource{client: client}
+
+	state := readDataSource(t, d, map[string]tftypes.Value{
+		"keys": tftypes.NewValue(tftypes.Set{ElementType: tftypes.String}, []tftypes.Value{
+			tftypes.NewValue(tftypes.String, "allow-keyless"),
+			tftypes.NewValue(tftypes.String, "anon-access"),
+		}),
+	})
+
+	var model RawSettingsDataSourceModel
+	if diags := state.Get(context.Background(), &model); diags.HasError() {
+		t.Fatalf("reading state: %s", diags)
+	}
+	var settings map[string]string
+	if diags := model.Settings.ElementsAs(context.Background(), &settings, false); diags.HasError() {
+		t.Fatalf("reading settings: %s", diags)
+	}
+	want := map[string]string{"allow-keyless": "true", "anon-access": "read-only"}
+	if !maps.Equal(settings, want) {
+		t.Errorf("settings = %v, want %v", settings, want)
+	}
+	if got := srv.Commands(); len(got) != 2 {
+		t.Errorf("commands = %q, want 2 settings reads", got)
+	}
+}
+
+func TestRawSettingsDataSourceRead_NotAdmin(t *testing.T) {
+	client, _ := newTestClient(t, func(string) (string, error) {
+		return "", errors.New("unauthorized")
+	})
+	d := &RawSettingsDataSource{client: client}
+
+	resp := runRead(t, d, map[string]tftypes.Value{
+		"keys": tftypes.NewValue(tftypes.Set{ElementType: tftypes.String}, []tftypes.Value{
+			tftypes.NewValue(tftypes.String, "anon-access"),
+		}),
+	})
+
+	if !resp.Diagnostics.HasError() {
+		t.Fatal("expected an error when the user is not an admin")
+	}
+	if got := resp.Diagnostics.Errors()[0].Summary(); got != "Admin required" {
+		t.Errorf("summary = %q, want %q", got, "Admin required")
+	}
+}
+
+// --- Pubkey Data Source Tests ---
+
+func TestPubkeyDataSourceMetadata(t *testing.T) {
+	d := NewPubkeyDataSource()
+	resp := &datasource.MetadataResponse{}
+
+	d.Metadata(context.Background(), datasource.MetadataRequest{ProviderTypeName: "softserve"}, resp)
+
+	if resp.TypeName != "softserve_pubkey" {
+		t.Errorf("TypeName = %q, want %q", resp.TypeName, "softserve_pubkey")
+	}
+}
+
+func TestPubkeyDataSourceRead(t *testing.T) {
+	tests := []struct {
+		name       string
+		output     string
+		wantAdmin  bool
+		wantAccess string
+		wantKeys   int
+	}{
+		{
+			name:       "admin",
+			output:     "Username: admin\nAdmin: true\nPublic keys:\n  ssh-ed25519 AAAA admin@host",
+			wantAdmin:  true,
+			wantAccess: "admin-access",
+			wantKeys:   1,
+		},
+		{
+			name:       "regular user",
+			output:     "Username: alice\nAdmin: false\nPublic keys:",
+			wantAccess: "",
+			wantKeys:   0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, srv := newTestClient(t, func(string) (string, error) { return tt.output, nil })
+			d := &PubkeyDataSource{client: client}
+
+			state := readDataSource(t, d, nil)
+
+			var model PubkeyDataSourceModel
+			if diags := state.Get(context.Background(), &model); diags.HasError() {
+				t.Fatalf("reading state: %s", diags)
+			}
+			if got := srv.Commands(); len(got) != 1 || got[0] != "info" {
+				t.Errorf("commands = %q, want [\"info\"]", got)
+			}
+			if model.Admin.ValueBool() != tt.wantAdmin {
+				t.Errorf("admin = %v, want %v", model.Admin.ValueBool(), tt.wantAdmin)
+			}
+			if model.Access.ValueString() != tt.wantAccess {
+				t.Errorf("access = %q, want %q", model.Access.ValueString(), tt.wantAccess)
+			}
+			if n := len(model.PublicKeys.Elements()); n != tt.wantKeys {
+				t.Errorf("got %d public keys, want %d", n, tt.wantKeys)
+			}
+		})
+	}
+}
+
+// --- Repository Collaborators Data Source Tests ---
+
+func TestRepositoriesDataSourceRead(t *testing.T) {
+	projects := map[string]string{
+		"team/service":     "Payments",
+		"team/sub/lib":     "Shared",
+		"teamwork":         "Payments",
+		"tools":            "Shared",
+		"team/service-api": "Payments",
+	}
+
+	tests := []struct {
+		name        string
+		config      map[string]tftypes.Value
+		wantID      string
+		want        []string
+		wantInfoFor int
+	}{
+		{
+			name:   "all",
+			config: map[string]tftypes.Value{},
+			wantID: "*",
+			want:   []string{"team/service", "team/service-api", "team/sub/lib", "teamwork", "tools"},
+		},
+		{
+			name:   "nested prefix",
+			config: map[string]tftypes.Value{"prefix": tftypes.NewValue(tftypes.String, "team/")},
+			wantID: "team/",
+			want:   []string{"team/service", "team/service-api", "team/sub/lib"},
+		},
+		{
+			name: "prefix and project name",
+			config: map[string]tftypes.Value{
+				"prefix":       tftypes.NewValue(tftypes.String, "team/"),
+				"project_name": tftypes.NewValue(tftypes.String, "Payments"),
+			},
+			wantID:      "team/",
+			want:        []string{"team/service", "team/service-api"},
+			wantInfoFor: 3,
+		},
+		{
+			name:   "no match",
+			config: map[string]tftypes.Value{"prefix": tftypes.NewValue(tftypes.String, "missing/")},
+			wantID: "missing/",
+			want:   []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, srv := newTestClient(t, func(cmd string) (string, error) {
+				if cmd == "repo list" {
+					return "tools\nteam/sub/lib\nteamwork\nteam/service\nteam/service-api\n", nil
+				}
+				name := strings.TrimPrefix(cmd, "repo info ")
+				return fmt.Sprintf("Project Name: %s\nRepository: %s\nPrivate: false\nHidden: false", projects[name], name), nil
+			})
+			d := &RepositoriesDataSource{client: client}
+
+			state := readDataSource(t, d, tt.config)
+
+			var model RepositoriesDataSourceModel
+			if diags := state.Get(context.Background(), &model); diags.HasError() {
+				t.Fatalf("reading state: %s", diags)
+			}
+			var got []string
+			if diags := model.Names.ElementsAs(context.Background(), &got, false); diags.HasError() {
+				t.Fatalf("reading names: %s", diags)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("names = %q, want %q", got, tt.want)
+			}
+			if model.ID.ValueString() != tt.wantID {
+				t.Errorf("id = %q, want %q", model.ID.ValueString(), tt.wantID)
+			}
+			if got := len(srv.Commands()) - 1; got != tt.wantInfoFor {
+				t.Errorf("read %d repositories, want %d: %q", got, tt.wantInfoFor, srv.Commands())
+			}
+		})
+	}
+}
+
+func TestRepositoryCollaboratorsDataSourceMetadata(t *testing.T) {
+	d := NewRepositoryCollaboratorsDataSource()
+	resp := &datasource.MetadataResponse{}
+
+	d.Metadata(context.Background(), datasource.MetadataRequest{ProviderTypeName: "softserve"}, resp)
+
+	if resp.TypeName != "softserve_repository_collaborators" {
+		t.Errorf("TypeName = %q, want %q", resp.TypeName, "softserve_repository_collaborators")
+	}
+}
+
+func TestRepositoryCollaboratorsDataSourceRead(t *testing.T) {
+	client, srv := newTestClient(t, func(string) (string, error) {
+		return "bob read-only\nalice\ncarol admin-access", nil
+	})
+	d := &RepositoryCollaboratorsDataSource{client: client}
+
+	state := readDataSource(t, d, map[string]tftypes.Value{
+		"repository": tftypes.NewValue(tftypes.String, "myrepo"),
+	})
+
+	var model RepositoryCollaboratorsDataSourceModel
+	if diags := state.Get(context.Background(), &model); diags.HasError() {
+		t.Fatalf("reading state: %s", diags)
+	}
+	if got := srv.Commands(); len(got) != 1 || got[0] != "repo collab list myrepo" {
+		t.Errorf("commands = %q, want [\"repo collab list myrepo\"]", got)
+	}
+	var got map[string]string
+	if diags := model.Collaborators.ElementsAs(context.Background(), &got, false); diags.HasError() {
+		t.Fatalf("reading collaborators: %s", diags)
+	}
+	want := map[string]string{"alice": "read-write", "bob": "read-only", "carol": "admin-access"}
+	if !maps.Equal(got, want) {
+		t.Errorf("collaborators = %v, want %v", got, want)
+	}
+	if model.ID.ValueString() != "myrepo" {
+		t.Errorf("id = %q, want %q", model.ID.ValueString(), "myrepo")
+	}
+}
+
+func TestRepositoryCollaboratorsDataSourceRead_RepositoryNotFound(t *testing.T) {
+	client, _ := newTestClient(t, func(string) (string, error) {
+		return "", errors.New("repository not found")
+	})
+	d := &RepositoryCollaboratorsDataSource{client: client}
+
+	resp := runRead(t, d, map[string]tftypes.Value{
+		"repository": tftypes.NewValue(tftypes.String, "gone"),
+	})
+
+	if !resp.Diagnostics.HasError() {
+		t.Fatal("expected an error for a missing repository")
+	}
+	if got := resp.Diagnostics.Errors()[0].Summary(); got != "Repository not found" {
+		t.Errorf("summary = %q, want %q", got, "Repository not found")
+	}
+}
+
+// --- User Data Source Tests ---
+
+func TestUserDataSourceMetadata(t *testing.T) {
+	d := NewUserDataSource()
+	resp := &datasource.MetadataResponse{}
+
+	d.Metadata(context.Background(), datasource.MetadataRequest{ProviderTypeName: "softserve"}, resp)
+
+	if resp.TypeName != "softserve_user" {
+		t.Errorf("TypeName = %q, want %q", resp.TypeName, "softserve_user")
+	}
+}
+
+func TestUserDataSourceRead(t *testing.T) {
+	tests := []struct {
+		name        string
+		output      string
+		wantCreated string // empty for null
+		wantUpdated string
+	}{
+		{
+			name:        "with timestamps",
+			output:      "Username: alice\nAdmin: true\nPublic keys:\n  ssh-ed25519 AAAA alice@host\nCreated At: 2024-03-01 09:30:00 +0000 UTC\nUpdated At: 2024-06-15 12:00:00 +0000 UTC",
+			wantCreated: "2024-03-01T09:30:00Z",
+			wantUpdated: "2024-06-15T12:00:00Z",
+		},
+		{
+			name:   "older server without timestamps",
+			output: "Username: alice\nAdmin: true\nPublic keys:\n  ssh-ed25519 AAAA alice@host",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, srv := newTestClient(t, func(string) (string, error) { return tt.output, nil })
+			d := &UserDataSource{client: client}
+
+			state := readDataSource(t, d, map[string]tftypes.Value{
+				"username": tftypes.NewValue(tftypes.String, "alice"),
+			})
+
+			var model UserDataSourceModel
+			if diags := state.Get(context.Background(), &model); diags.HasError() {
+				t.Fatalf("reading state: %s", diags)
+			}
+			if got := srv.Commands(); len(got) != 1 || got[0] != "user info alice" {
+				t.Errorf("commands = %q, want [\"user info alice\"]", got)
+			}
+			if !model.Admin.ValueBool() {
+				t.Error("admin = false, want true")
+			}
+			if len(model.PublicKeys.Elements()) != 1 {
+				t.Errorf("public_keys has %d elements, want 1", len(model.PublicKeys.Elements()))
+			}
+			for attr, tc := range map[string]struct {
+				got  types.String
+				want string
+			}{
+				"created_at": {model.CreatedAt, tt.wantCreated},
+				"updated_at": {model.UpdatedAt, tt.wantUpdated},
+			} {
+				if tc.want == "" {
+					if !tc.got.IsNull() {
+						t.Errorf("%s = %v, want null", attr, tc.got)
+					}
+				} else if tc.got.ValueString() != tc.want {
+					t.Errorf("%s = %q, want %q", attr, tc.got.ValueString(), tc.want)
+				}
+			}
+		})
+	}
+}
+
+func TestUserDataSourceRead_NotFound(t *testing.T) {
+	client, _ := newTestClient(t, func(string) (string, error) {
+		return "", errors.New("user not found")
+	})
+	d := &UserDataSource{client: client}
+
+	resp := runRead(t, d, map[string]tftypes.Value{
+		"username": tftypes.NewValue(tftypes.String, "ghost"),
+	})
+
+	if !resp.Diagnostics.HasError() {
+		t.Fatal("expected an error for a missing user")
+	}
+	if got := resp.Diagnostics.Errors()[0].Summary(); got != "User not found" {
+		t.Errorf("summary = %q, want %q", got, "User not found")
+	}
+}
@@ -0,0 +1,109 @@
+package datasource
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/ssoriche/terraform-provider-soft-serve/internal/ssh"
+)
+
+var _ datasource.DataSource = &PubkeyDataSource{}
+
+type PubkeyDataSource struct {
+	client *ssh.Client
+}
+
+type PubkeyDataSourceModel struct {
+	ID         types.String `tfsdk:"id"`
+	Username   types.String `tfsdk:"username"`
+	Admin      types.Bool   `tfsdk:"admin"`
+	Access     types.String `tfsdk:"access"`
+	PublicKeys types.List   `tfsdk:"public_keys"`
+}
+
+func NewPubkeyDataSource() datasource.DataSource {
+	return &PubkeyDataSource{}
+}
+
+func (d *PubkeyDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_pubkey"
+}
+
+func (d *PubkeyDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Reports the Soft Serve user the provider authenticates as, to confirm it is the expected account.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "Authenticated username.",
+				Computed:    true,
+			},
+			"username": schema.StringAttribute{
+				Description: "Username the configured key authenticates as.",
+				Computed:    true,
+			},
+			"admin": schema.BoolAttribute{
+				Description: "Whether the authenticated user is a server admin.",
+				Computed:    true,
+			},
+			"access": schema.StringAttribute{
+				Description: "Server-wide access level of the authenticated user: admin-access for admins, otherwise the level the server reports, or empty if it reports none.",
+				Computed:    true,
+			},
+			"public_keys": schema.ListAttribute{
+				Description: "Public keys registered to the authenticated user.",
+				Computed:    true,
+				ElementType: types.StringType,
+			},
+		},
+	}
+}
+
+func (d *PubkeyDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(*ssh.Client)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *ssh.Client, got: %T", req.ProviderData))
+		return
+	}
+	d.client = client
+}
+
+func (d *PubkeyDataSource) Read(ctx context.Context, _ datasource.ReadRequest, resp *datasource.ReadResponse) {
+	info, err := d.client.Info(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError("Error reading authenticated user", err.Error())
+		return
+	}
+
+	access := info.Access
+	if info.Admin {
+		access = string(ssh.AccessLevelAdminAccess)
+	}
+
+	keys := info.PublicKeys
+	if keys == nil {
+		keys = []string{}
+	}
+	publicKeys, diags := types.ListValueFrom(ctx, types.StringType, keys)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	state := PubkeyDataSourceModel{
+		ID:         types.StringValue(info.Username),
+		Username:   types.StringValue(info.Username),
+		Admin:      types.BoolValue(info.Admin),
+		Access:     types.StringValue(access),
+		PublicKeys: publicKeys,
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
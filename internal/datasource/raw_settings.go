@@ -0,0 +1,113 @@
+package datasource
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/ssoriche/terraform-provider-soft-serve/internal/ssh"
+)
+
+var _ datasource.DataSource = &RawSettingsDataSource{}
+
+// RawSettingsDataSource reads server settings by name, for settings the
+// provider doesn't model yet.
+type RawSettingsDataSource struct {
+	client *ssh.Client
+}
+
+type RawSettingsDataSourceModel struct {
+	ID       types.String `tfsdk:"id"`
+	Keys     types.Set    `tfsdk:"keys"`
+	Settings types.Map    `tfsdk:"settings"`
+}
+
+func NewRawSettingsDataSource() datasource.DataSource {
+	return &RawSettingsDataSource{}
+}
+
+func (d *RawSettingsDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_raw_settings"
+}
+
+func (d *RawSettingsDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Reads Soft Serve server settings by name, as printed by `settings <key>`, including ones softserve_settings doesn't model yet.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "Always \"settings\".",
+				Computed:    true,
+			},
+			"keys": schema.SetAttribute{
+				Description: "Names of the settings to read, e.g. \"allow-keyless\".",
+				Required:    true,
+				ElementType: types.StringType,
+				Validators: []validator.Set{
+					setvalidator.ValueStringsAre(stringvalidator.LengthAtLeast(1)),
+				},
+			},
+			"settings": schema.MapAttribute{
+				Description: "Value of each setting in keys, keyed by name, as the server prints it.",
+				Computed:    true,
+				ElementType: types.StringType,
+			},
+		},
+	}
+}
+
+func (d *RawSettingsDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(*ssh.Client)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *ssh.Client, got: %T", req.ProviderData))
+		return
+	}
+	d.client = client
+}
+
+func (d *RawSettingsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var config RawSettingsDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var keys []string
+	resp.Diagnostics.Append(config.Keys.ElementsAs(ctx, &keys, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	values := make(map[string]string, len(keys))
+	for _, key := range keys {
+		value, err := d.client.SettingsGet(ctx, key)
+		if err != nil {
+			addSettingsError(resp, key, err)
+			return
+		}
+		values[key] = value
+	}
+
+	settings, diags := types.MapValueFrom(ctx, types.StringType, values)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	state := RawSettingsDataSourceModel{
+		ID:       types.StringValue("settings"),
+		Keys:     config.Keys,
+		Settings: settings,
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
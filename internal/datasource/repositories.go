@@ -0,0 +1,128 @@
+package datasource
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/ssoriche/terraform-provider-soft-serve/internal/ssh"
+)
+
+var _ datasource.DataSource = &RepositoriesDataSource{}
+
+// RepositoriesDataSource lists the repositories on the server, optionally
+// filtered by name prefix and project name.
+type RepositoriesDataSource struct {
+	client *ssh.Client
+}
+
+type RepositoriesDataSourceModel struct {
+	ID          types.String `tfsdk:"id"`
+	Prefix      types.String `tfsdk:"prefix"`
+	ProjectName types.String `tfsdk:"project_name"`
+	Names       types.List   `tfsdk:"names"`
+}
+
+func NewRepositoriesDataSource() datasource.DataSource {
+	return &RepositoriesDataSource{}
+}
+
+func (d *RepositoriesDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_repositories"
+}
+
+func (d *RepositoriesDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Lists the repositories the configured user can see. Nested repositories are listed by their full name, e.g. \"team/service\".",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "Identifier (the prefix, or \"*\" without one).",
+				Computed:    true,
+			},
+			"prefix": schema.StringAttribute{
+				Description: "Only list repositories whose full name starts with this. Include the trailing slash, e.g. \"team/\", to list only the repositories nested under team.",
+				Optional:    true,
+			},
+			"project_name": schema.StringAttribute{
+				Description: "Only list repositories with this project name. Each listed repository is read to check it, so combine it with prefix on large servers.",
+				Optional:    true,
+			},
+			"names": schema.ListAttribute{
+				Description: "Full names of the matching repositories, sorted.",
+				Computed:    true,
+				ElementType: types.StringType,
+			},
+		},
+	}
+}
+
+func (d *RepositoriesDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(*ssh.Client)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *ssh.Client, got: %T", req.ProviderData))
+		return
+	}
+	d.client = client
+}
+
+func (d *RepositoriesDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var config RepositoriesDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	repos, err := d.client.RepoList(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError("Error listing repositories", err.Error())
+		return
+	}
+
+	// `repo list` takes no filter, so both are applied here
+	prefix := config.Prefix.ValueString()
+	names := []string{}
+	for _, repo := range repos {
+		if !strings.HasPrefix(repo, prefix) {
+			continue
+		}
+		if !config.ProjectName.IsNull() {
+			info, err := d.client.RepoInfo(ctx, repo)
+			if err != nil {
+				resp.Diagnostics.AddError("Error reading repository", err.Error())
+				return
+			}
+			if info.ProjectName != config.ProjectName.ValueString() {
+				continue
+			}
+		}
+		names = append(names, repo)
+	}
+	slices.Sort(names)
+
+	list, diags := types.ListValueFrom(ctx, types.StringType, names)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	id := prefix
+	if id == "" {
+		id = "*"
+	}
+	state := RepositoriesDataSourceModel{
+		ID:          types.StringValue(id),
+		Prefix:      config.Prefix,
+		ProjectName: config.ProjectName,
+		Names:       list,
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
@@ -0,0 +1,244 @@
+package datasource
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/ssoriche/terraform-provider-soft-serve/internal/ssh"
+)
+
+var _ datasource.DataSource = &RepositoryDataSource{}
+
+type RepositoryDataSource struct {
+	client *ssh.Client
+}
+
+type RepositoryDataSourceModel struct {
+	ID          types.String `tfsdk:"id"`
+	Name        types.String `tfsdk:"name"`
+	Description types.String `tfsdk:"description"`
+	ProjectName types.String `tfsdk:"project_name"`
+	Private     types.Bool   `tfsdk:"private"`
+	Hidden      types.Bool   `tfsdk:"hidden"`
+	Mirror      types.Bool   `tfsdk:"mirror"`
+	UpstreamURL types.String `tfsdk:"upstream_url"`
+	Owner       types.String `tfsdk:"owner"`
+	Access      types.String `tfsdk:"access"`
+	IsEmpty     types.Bool   `tfsdk:"is_empty"`
+
+	CollaboratorCount types.Int64 `tfsdk:"collaborator_count"`
+	Collaborators     types.Map   `tfsdk:"collaborators"`
+
+	SSHCloneURL  types.String `tfsdk:"ssh_clone_url"`
+	HTTPCloneURL types.String `tfsdk:"http_clone_url"`
+	CreatedAt    types.String `tfsdk:"created_at"`
+	UpdatedAt    types.String `tfsdk:"updated_at"`
+	LastPush     types.String `tfsdk:"last_push"`
+	SizeBytes    types.Int64  `tfsdk:"size_bytes"`
+}
+
+func NewRepositoryDataSource() datasource.DataSource {
+	return &RepositoryDataSource{}
+}
+
+func (d *RepositoryDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_repository"
+}
+
+func (d *RepositoryDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Reads an existing Soft Serve git repository.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "Repository identifier: the name as the server reports it, which may differ from name in case.",
+				Computed:    true,
+			},
+			"name": schema.StringAttribute{
+				Description: "Repository name.",
+				Required:    true,
+			},
+			"description": schema.StringAttribute{
+				Description: "Repository description.",
+				Computed:    true,
+			},
+			"project_name": schema.StringAttribute{
+				Description: "Project name for the repository.",
+				Computed:    true,
+			},
+			"private": schema.BoolAttribute{
+				Description: "Whether the repository is private. Null when the server doesn't report it.",
+				Computed:    true,
+			},
+			"hidden": schema.BoolAttribute{
+				Description: "Whether the repository is hidden.",
+				Computed:    true,
+			},
+			"mirror": schema.BoolAttribute{
+				Description: "Whether the repository is a mirror.",
+				Computed:    true,
+			},
+			"upstream_url": schema.StringAttribute{
+				Description: "URL a mirror pulls from, without any credentials. Null unless the repository is a mirror and the server reports its upstream.",
+				Computed:    true,
+			},
+			"owner": schema.StringAttribute{
+				Description: "Username of the repository owner.",
+				Computed:    true,
+			},
+			"access": schema.StringAttribute{
+				Description: "Access level of the configured user on the repository, e.g. read-only or admin-access. When the server doesn't report it, it is worked out from the user's admin status, the repository owner and the collaborator list, and is empty if none of those grant access.",
+				Computed:    true,
+			},
+			"is_empty": schema.BoolAttribute{
+				Description: "Whether the repository has no commits yet, i.e. no branches.",
+				Computed:    true,
+			},
+			"collaborator_count": schema.Int64Attribute{
+				Description: "Number of collaborators on the repository. Null when the configured user isn't allowed to list them.",
+				Computed:    true,
+			},
+			"collaborators": schema.MapAttribute{
+				Description: "Access level of each collaborator, keyed by username; collaborators listed without a level have read-write. Null when the configured user isn't allowed to list them.",
+				Computed:    true,
+				ElementType: types.StringType,
+			},
+			"ssh_clone_url": schema.StringAttribute{
+				Description: "URL for cloning the repository over SSH.",
+				Computed:    true,
+			},
+			"http_clone_url": schema.StringAttribute{
+				Description: "URL for cloning the repository over HTTP. Null unless the provider's http_base_url is set.",
+				Computed:    true,
+			},
+			"created_at": schema.StringAttribute{
+				Description: "When the repository was created, in RFC3339 format. Null when the server doesn't report it.",
+				Computed:    true,
+			},
+			"updated_at": schema.StringAttribute{
+				Description: "When the repository was last updated, in RFC3339 format. Null when the server doesn't report it.",
+				Computed:    true,
+			},
+			"last_push": schema.StringAttribute{
+				Description: "When the repository was last pushed to, in RFC3339 format. Null when the server doesn't track pushes.",
+				Computed:    true,
+			},
+			"size_bytes": schema.Int64Attribute{
+				Description: "Disk size of the repository in bytes. Null when the server doesn't report it.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func (d *RepositoryDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(*ssh.Client)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *ssh.Client, got: %T", req.ProviderData))
+		return
+	}
+	d.client = client
+}
+
+func (d *RepositoryDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var config RepositoryDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	name := config.Name.ValueString()
+	info, err := d.client.RepoInfo(ctx, name)
+	if err != nil {
+		resp.Diagnostics.AddError("Error reading repository", err.Error())
+		return
+	}
+
+	state := RepositoryDataSourceModel{
+		ID:          types.StringValue(info.Repository),
+		Name:        config.Name,
+		Description: types.StringValue(info.Description),
+		ProjectName: types.StringValue(info.ProjectName),
+		Private:     types.BoolNull(),
+		Hidden:      types.BoolValue(info.Hidden),
+		Mirror:      types.BoolValue(info.Mirror),
+		UpstreamURL: optionalString(info.MirrorURL),
+		Owner:       types.StringValue(info.Owner),
+		Access:      types.StringValue(info.Access),
+		IsEmpty:     types.BoolValue(len(info.Branches) == 0),
+
+		SSHCloneURL:  types.StringValue(d.client.SSHCloneURL(info.Repository)),
+		HTTPCloneURL: types.StringNull(),
+		CreatedAt:    optionalString(info.CreatedAt),
+		UpdatedAt:    optionalString(info.UpdatedAt),
+		LastPush:     optionalString(info.LastPush),
+		SizeBytes:    types.Int64Null(),
+	}
+	if u := d.client.HTTPCloneURL(info.Repository); u != "" {
+		state.HTTPCloneURL = types.StringValue(u)
+	}
+	if info.HasPrivate {
+		state.Private = types.BoolValue(info.Private)
+	}
+	if info.HasSize {
+		state.SizeBytes = types.Int64Value(info.Size)
+	}
+
+	// Listing collaborators takes more than read access, which is all a data
+	// source otherwise needs, so a refusal only leaves the collaborators
+	// unknown.
+	state.CollaboratorCount = types.Int64Null()
+	state.Collaborators = types.MapNull(types.StringType)
+	collabs, err := d.client.CollabList(ctx, info.Repository)
+	switch {
+	case err == nil:
+		state.CollaboratorCount = types.Int64Value(int64(len(collabs)))
+		collaborators, diags := types.MapValueFrom(ctx, types.StringType, collaboratorLevels(collabs))
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		state.Collaborators = collaborators
+	case !ssh.IsUnauthorized(err):
+		resp.Diagnostics.AddError("Error listing collaborators", err.Error())
+		return
+	}
+
+	if info.Access == "" {
+		state.Access = types.StringValue(d.derivedAccess(ctx, info, collabs))
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// derivedAccess works out the configured user's access level on a repository
+// for servers whose `repo info` doesn't report it: admins and the owner have
+// admin access, and collaborators their listed level. It returns "" when none
+// of those apply or the user can't be identified.
+func (d *RepositoryDataSource) derivedAccess(ctx context.Context, info *ssh.RepoInfoResult, collabs []ssh.CollabEntry) string {
+	me, err := d.client.Info(ctx)
+	if err != nil {
+		return ""
+	}
+	if me.Admin || strings.EqualFold(me.Username, info.Owner) {
+		return string(ssh.AccessLevelAdminAccess)
+	}
+	for _, c := range collabs {
+		if !strings.EqualFold(c.Username, me.Username) {
+			continue
+		}
+		if level, ok := ssh.NormalizeAccessLevel(c.AccessLevel); ok {
+			return string(level)
+		}
+		return string(ssh.AccessLevelReadWrite)
+	}
+	return ""
+}
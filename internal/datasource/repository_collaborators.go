@@ -0,0 +1,116 @@
+package datasource
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/ssoriche/terraform-provider-soft-serve/internal/ssh"
+)
+
+var _ datasource.DataSource = &RepositoryCollaboratorsDataSource{}
+
+// RepositoryCollaboratorsDataSource reads the current collaborators of a
+// repository, e.g. to write configuration for an imported repository.
+type RepositoryCollaboratorsDataSource struct {
+	client *ssh.Client
+}
+
+type RepositoryCollaboratorsDataSourceModel struct {
+	ID            types.String `tfsdk:"id"`
+	Repository    types.String `tfsdk:"repository"`
+	Collaborators types.Map    `tfsdk:"collaborators"`
+}
+
+func NewRepositoryCollaboratorsDataSource() datasource.DataSource {
+	return &RepositoryCollaboratorsDataSource{}
+}
+
+func (d *RepositoryCollaboratorsDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_repository_collaborators"
+}
+
+func (d *RepositoryCollaboratorsDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Reads the collaborators of an existing Soft Serve repository. The collaborators map has the same shape as softserve_repository_collaborators, and can drive for_each over softserve_repository_collaborator, which makes it handy for writing configuration for imported repositories.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "Identifier (same as repository).",
+				Computed:    true,
+			},
+			"repository": schema.StringAttribute{
+				Description: "Repository name.",
+				Required:    true,
+			},
+			"collaborators": schema.MapAttribute{
+				Description: "Access level of each collaborator, keyed by username.",
+				Computed:    true,
+				ElementType: types.StringType,
+			},
+		},
+	}
+}
+
+func (d *RepositoryCollaboratorsDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(*ssh.Client)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *ssh.Client, got: %T", req.ProviderData))
+		return
+	}
+	d.client = client
+}
+
+func (d *RepositoryCollaboratorsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var config RepositoryCollaboratorsDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	repo := config.Repository.ValueString()
+	collabs, err := d.client.CollabList(ctx, repo)
+	if err != nil {
+		if ssh.IsNotFound(err) {
+			resp.Diagnostics.AddAttributeError(path.Root("repository"), "Repository not found",
+				fmt.Sprintf("Repository %q does not exist.", repo))
+			return
+		}
+		resp.Diagnostics.AddError("Error listing collaborators", err.Error())
+		return
+	}
+
+	collaborators, diags := types.MapValueFrom(ctx, types.StringType, collaboratorLevels(collabs))
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	state := RepositoryCollaboratorsDataSourceModel{
+		ID:            types.StringValue(repo),
+		Repository:    types.StringValue(repo),
+		Collaborators: collaborators,
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// collaboratorLevels maps each collaborator's username to its access level.
+// Entries without a level get read-write, the server's default.
+func collaboratorLevels(collabs []ssh.CollabEntry) map[string]string {
+	levels := make(map[string]string, len(collabs))
+	for _, c := range collabs {
+		level := c.AccessLevel
+		if level == "" {
+			level = string(ssh.AccessLevelReadWrite)
+		}
+		levels[c.Username] = level
+	}
+	return levels
+}
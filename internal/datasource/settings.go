@@ -0,0 +1,101 @@
+package datasource
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/ssoriche/terraform-provider-soft-serve/internal/ssh"
+)
+
+var _ datasource.DataSource = &SettingsDataSource{}
+
+type SettingsDataSource struct {
+	client *ssh.Client
+}
+
+type SettingsDataSourceModel struct {
+	ID           types.String `tfsdk:"id"`
+	AllowKeyless types.Bool   `tfsdk:"allow_keyless"`
+	AnonAccess   types.String `tfsdk:"anon_access"`
+}
+
+func NewSettingsDataSource() datasource.DataSource {
+	return &SettingsDataSource{}
+}
+
+func (d *SettingsDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_settings"
+}
+
+func (d *SettingsDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Reads the Soft Serve server settings without managing them. Use softserve_server_settings to change them.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "Always \"settings\".",
+				Computed:    true,
+			},
+			"allow_keyless": schema.BoolAttribute{
+				Description: "Whether keyless access to repositories is allowed.",
+				Computed:    true,
+			},
+			"anon_access": schema.StringAttribute{
+				Description: "Default access level for anonymous users.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func (d *SettingsDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(*ssh.Client)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *ssh.Client, got: %T", req.ProviderData))
+		return
+	}
+	d.client = client
+}
+
+func (d *SettingsDataSource) Read(ctx context.Context, _ datasource.ReadRequest, resp *datasource.ReadResponse) {
+	allowKeyless, err := d.client.SettingsGetAllowKeyless(ctx)
+	if err != nil {
+		addSettingsError(resp, "allow-keyless", err)
+		return
+	}
+
+	anonAccess, err := d.client.SettingsGetAnonAccess(ctx)
+	if err != nil {
+		addSettingsError(resp, "anon-access", err)
+		return
+	}
+	if level, ok := ssh.NormalizeAccessLevel(anonAccess); ok {
+		anonAccess = string(level)
+	}
+
+	state := SettingsDataSourceModel{
+		ID:           types.StringValue("settings"),
+		AllowKeyless: types.BoolValue(allowKeyless),
+		AnonAccess:   types.StringValue(anonAccess),
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// addSettingsError reports a failure to read setting, calling out the common
+// case of the configured user not being an admin.
+func addSettingsError(resp *datasource.ReadResponse, setting string, err error) {
+	if ssh.IsUnauthorized(err) {
+		resp.Diagnostics.AddError("Admin required",
+			fmt.Sprintf("The server refused to report %s to the configured user. Reading server settings requires a Soft Serve admin on this server: %s", setting, err))
+		return
+	}
+	resp.Diagnostics.AddError("Error reading "+setting, err.Error())
+}
@@ -0,0 +1,135 @@
+package datasource
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/ssoriche/terraform-provider-soft-serve/internal/ssh"
+)
+
+var _ datasource.DataSource = &UserDataSource{}
+
+type UserDataSource struct {
+	client *ssh.Client
+}
+
+type UserDataSourceModel struct {
+	ID         types.String `tfsdk:"id"`
+	Username   types.String `tfsdk:"username"`
+	Admin      types.Bool   `tfsdk:"admin"`
+	PublicKeys types.List   `tfsdk:"public_keys"`
+	CreatedAt  types.String `tfsdk:"created_at"`
+	UpdatedAt  types.String `tfsdk:"updated_at"`
+}
+
+func NewUserDataSource() datasource.DataSource {
+	return &UserDataSource{}
+}
+
+func (d *UserDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_user"
+}
+
+func (d *UserDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Reads an existing Soft Serve user.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "User identifier (same as username).",
+				Computed:    true,
+			},
+			"username": schema.StringAttribute{
+				Description: "Username.",
+				Required:    true,
+			},
+			"admin": schema.BoolAttribute{
+				Description: "Whether the user is an admin.",
+				Computed:    true,
+			},
+			"public_keys": schema.ListAttribute{
+				Description: "Public keys registered to the user.",
+				Computed:    true,
+				ElementType: types.StringType,
+			},
+			"created_at": schema.StringAttribute{
+				Description: "When the user was created, in RFC3339 format. Null when the server doesn't report it.",
+				Computed:    true,
+			},
+			"updated_at": schema.StringAttribute{
+				Description: "When the user was last updated, in RFC3339 format. Null when the server doesn't report it.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func (d *UserDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(*ssh.Client)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *ssh.Client, got: %T", req.ProviderData))
+		return
+	}
+	d.client = client
+}
+
+func (d *UserDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var config UserDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	username := config.Username.ValueString()
+	info, err := d.client.UserInfo(ctx, username)
+	if err != nil {
+		if ssh.IsNotFound(err) {
+			resp.Diagnostics.AddAttributeError(path.Root("username"), "User not found",
+				fmt.Sprintf("User %q does not exist.", username))
+			return
+		}
+		resp.Diagnostics.AddError("Error reading user", err.Error())
+		return
+	}
+
+	// List keys as the server printed them, comments included
+	keys := make([]string, len(info.PublicKeys))
+	for i, key := range info.PublicKeys {
+		keys[i] = key
+		if comment := info.PublicKeyComments[i]; comment != "" {
+			keys[i] += " " + comment
+		}
+	}
+	publicKeys, diags := types.ListValueFrom(ctx, types.StringType, keys)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	state := UserDataSourceModel{
+		ID:         types.StringValue(info.Username),
+		Username:   types.StringValue(info.Username),
+		Admin:      types.BoolValue(info.Admin),
+		PublicKeys: publicKeys,
+		CreatedAt:  optionalString(info.CreatedAt),
+		UpdatedAt:  optionalString(info.UpdatedAt),
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// optionalString returns s as a string value, or null when it is empty.
+func optionalString(s string) types.String {
+	if s == "" {
+		return types.StringNull()
+	}
+	return types.StringValue(s)
+}
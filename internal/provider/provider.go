@@ -2,20 +2,29 @@ package provider
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net/url"
 	"os"
 	"os/user"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/float64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"golang.org/x/crypto/ssh/knownhosts"
 
 	"github.com/ssoriche/terraform-provider-soft-serve/internal/ssh"
 
+	softservedatasource "github.com/ssoriche/terraform-provider-soft-serve/internal/datasource"
 	softserveresource "github.com/ssoriche/terraform-provider-soft-serve/internal/resource"
 )
 
@@ -26,12 +35,34 @@ type SoftServeProvider struct {
 }
 
 type SoftServeProviderModel struct {
-	Host           types.String `tfsdk:"host"`
-	Port           types.Int64  `tfsdk:"port"`
-	Username       types.String `tfsdk:"username"`
-	PrivateKeyPath types.String `tfsdk:"private_key_path"`
-	IdentityFile   types.String `tfsdk:"identity_file"`
-	UseAgent       types.Bool   `tfsdk:"use_agent"`
+	Host                types.String  `tfsdk:"host"`
+	Port                types.Int64   `tfsdk:"port"`
+	Username            types.String  `tfsdk:"username"`
+	PrivateKeyPath      types.String  `tfsdk:"private_key_path"`
+	IdentityFile        types.String  `tfsdk:"identity_file"`
+	IdentityFiles       types.List    `tfsdk:"identity_files"`
+	IdentityFingerprint types.String  `tfsdk:"identity_fingerprint"`
+	UseAgent            types.Bool    `tfsdk:"use_agent"`
+	IdentitiesOnly      types.Bool    `tfsdk:"identities_only"`
+	KnownHostsFile      types.String  `tfsdk:"known_hosts_file"`
+	CommandPrefix       types.String  `tfsdk:"command_prefix"`
+	Subsystem           types.String  `tfsdk:"subsystem"`
+	SSHEnv              types.Map     `tfsdk:"ssh_env"`
+	HTTPBaseURL         types.String  `tfsdk:"http_base_url"`
+	ProxyCommand        types.String  `tfsdk:"proxy_command"`
+	UnixSocket          types.String  `tfsdk:"unix_socket"`
+	MaxRetries          types.Int64   `tfsdk:"max_retries"`
+	RetryBaseDelay      types.String  `tfsdk:"retry_base_delay"`
+	RetryMaxDelay       types.String  `tfsdk:"retry_max_delay"`
+	BannerTimeout       types.String  `tfsdk:"banner_timeout"`
+	AuthTimeout         types.String  `tfsdk:"auth_timeout"`
+	CommandsPerSecond   types.Float64 `tfsdk:"commands_per_second"`
+	MaxConnections      types.Int64   `tfsdk:"max_connections"`
+	ClientVersion       types.String  `tfsdk:"client_version"`
+
+	SkipConnectionCheck types.Bool `tfsdk:"skip_connection_check"`
+	VerboseErrors       types.Bool `tfsdk:"verbose_errors"`
+	ProtectLastAdmin    types.Bool `tfsdk:"protect_last_admin"`
 }
 
 func New(version string) func() provider.Provider {
@@ -58,21 +89,119 @@ func (p *SoftServeProvider) Schema(_ context.Context, _ provider.SchemaRequest,
 			"port": schema.Int64Attribute{
 				Description: "Soft Serve SSH port. Can also be set with SOFT_SERVE_PORT. Defaults to 23231.",
 				Optional:    true,
+				Validators: []validator.Int64{
+					int64validator.Between(1, 65535),
+				},
 			},
 			"username": schema.StringAttribute{
 				Description: "SSH username. Can also be set with SOFT_SERVE_USER. Defaults to current OS user.",
 				Optional:    true,
 			},
 			"private_key_path": schema.StringAttribute{
-				Description: "Path to SSH private key file. SOFT_SERVE_PRIVATE_KEY env var (key contents) takes precedence.",
+				Description: "Path to SSH private key file. SOFT_SERVE_PRIVATE_KEY env var (key contents) takes precedence. Either may hold several concatenated PEM keys, which are offered in order.",
 				Optional:    true,
 			},
 			"identity_file": schema.StringAttribute{
 				Description: "Path to SSH public key file used to select which agent key to offer (like OpenSSH IdentityFile). Can also be set with SOFT_SERVE_IDENTITY_FILE.",
 				Optional:    true,
 			},
+			"identity_files": schema.ListAttribute{
+				Description: "Paths to SSH public key files used to select which agent key to offer, tried in order (like multiple OpenSSH IdentityFile entries). Checked after identity_file when both are set.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"identity_fingerprint": schema.StringAttribute{
+				Description: "SHA256 fingerprint of the agent key to offer (e.g. \"SHA256:...\" as printed by ssh-keygen -l), for when its public key file isn't available. Checked after identity_file and identity_files. Can also be set with SOFT_SERVE_IDENTITY_FINGERPRINT.",
+				Optional:    true,
+			},
 			"use_agent": schema.BoolAttribute{
-				Description: "Whether to use SSH agent for authentication. Can also be set with SOFT_SERVE_USE_AGENT. Defaults to true.",
+				Description: "Whether to use SSH agent for authentication. Can also be set with SOFT_SERVE_USE_AGENT. Defaults to true, except when a private key is configured, in which case the agent is only used if this is set explicitly.",
+				Optional:    true,
+			},
+			"identities_only": schema.BoolAttribute{
+				Description: "Never consult the SSH agent when a private key is configured, even if use_agent is true, like OpenSSH's IdentitiesOnly. Avoids offering the server extra keys. Can also be set with SOFT_SERVE_IDENTITIES_ONLY.",
+				Optional:    true,
+			},
+			"known_hosts_file": schema.StringAttribute{
+				Description: "Path to a known_hosts file used to verify the server's SSH host key. Can also be set with SOFT_SERVE_KNOWN_HOSTS_FILE. When unset, host keys are not verified.",
+				Optional:    true,
+			},
+			"command_prefix": schema.StringAttribute{
+				Description: "Prefix prepended to every Soft Serve command, for servers behind a wrapper or forced command (e.g. \"soft\"). Can also be set with SOFT_SERVE_COMMAND_PREFIX.",
+				Optional:    true,
+			},
+			"subsystem": schema.StringAttribute{
+				Description: "SSH subsystem to send commands to instead of running them with an exec request, for deployments that only expose Soft Serve as a subsystem. Can also be set with SOFT_SERVE_SUBSYSTEM. When unset, commands are run with exec.",
+				Optional:    true,
+			},
+			"ssh_env": schema.MapAttribute{
+				Description: "Environment variables to set on each SSH session, for forced-command wrappers that read them. The server must accept them with AcceptEnv; variables it refuses are logged as warnings and otherwise ignored.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"proxy_command": schema.StringAttribute{
+				Description: "Command whose stdin and stdout are used as the connection to the server instead of dialing it directly, like OpenSSH's ProxyCommand (e.g. \"cloudflared access ssh --hostname %h\"). %h, %p and %r expand to the host, port and username. Can also be set with SOFT_SERVE_PROXY_COMMAND.",
+				Optional:    true,
+			},
+			"unix_socket": schema.StringAttribute{
+				Description: "Path of a Unix domain socket to connect to instead of dialing host and port over TCP, e.g. when Soft Serve runs in a sidecar. host and port are still used to look up the server's host key. Conflicts with proxy_command. Can also be set with SOFT_SERVE_UNIX_SOCKET.",
+				Optional:    true,
+			},
+			"http_base_url": schema.StringAttribute{
+				Description: "Base URL of the Soft Serve HTTP endpoint (e.g. \"https://git.example.com\"), used to build repositories' http_clone_url. Can also be set with SOFT_SERVE_HTTP_BASE_URL.",
+				Optional:    true,
+			},
+			"max_retries": schema.Int64Attribute{
+				Description: "Number of times to retry opening the SSH connection when the server is unreachable. Can also be set with SOFT_SERVE_MAX_RETRIES. Defaults to 3.",
+				Optional:    true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+				},
+			},
+			"retry_base_delay": schema.StringAttribute{
+				Description: "Base delay between connection retries as a Go duration (e.g. \"250ms\"). Each retry waits a random time up to the base delay doubled per attempt. Can also be set with SOFT_SERVE_RETRY_BASE_DELAY. Defaults to 250ms.",
+				Optional:    true,
+			},
+			"retry_max_delay": schema.StringAttribute{
+				Description: "Maximum delay between connection retries as a Go duration (e.g. \"5s\"). Can also be set with SOFT_SERVE_RETRY_MAX_DELAY. Defaults to 5s.",
+				Optional:    true,
+			},
+			"banner_timeout": schema.StringAttribute{
+				Description: "How long to wait, once connected, for the server to identify itself, as a Go duration (e.g. \"15s\"). \"0s\" waits forever. Can also be set with SOFT_SERVE_BANNER_TIMEOUT. Defaults to 15s.",
+				Optional:    true,
+			},
+			"auth_timeout": schema.StringAttribute{
+				Description: "How long the whole SSH handshake, including authentication, may take, as a Go duration (e.g. \"30s\"). \"0s\" waits forever. Can also be set with SOFT_SERVE_AUTH_TIMEOUT. Defaults to 30s.",
+				Optional:    true,
+			},
+			"commands_per_second": schema.Float64Attribute{
+				Description: "Maximum rate at which commands are sent to the server, for servers with strict rate limits. Fractions such as 0.5 are allowed; 0 or unset means no limit. Can also be set with SOFT_SERVE_COMMANDS_PER_SECOND.",
+				Optional:    true,
+				Validators: []validator.Float64{
+					float64validator.AtLeast(0),
+				},
+			},
+			"max_connections": schema.Int64Attribute{
+				Description: "Maximum number of SSH connections to open at once, so Terraform's parallel resource operations don't all share one connection. Further connections are only opened while every open one is busy. Can also be set with SOFT_SERVE_MAX_CONNECTIONS. Defaults to 1.",
+				Optional:    true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"client_version": schema.StringAttribute{
+				Description: "SSH identification string the provider sends to the server, so its connections can be told apart in server logs (e.g. \"SSH-2.0-terraform\"). Must start with \"SSH-2.0-\". Can also be set with SOFT_SERVE_CLIENT_VERSION. Defaults to the SSH library's own.",
+				Optional:    true,
+			},
+			"skip_connection_check": schema.BoolAttribute{
+				Description: "Skip connecting to the server when the provider is configured. By default the provider checks the connection and credentials, including that the key is registered to a user, up front so problems are reported before any resource is touched. Can also be set with SOFT_SERVE_SKIP_CONNECTION_CHECK.",
+				Optional:    true,
+			},
+			"verbose_errors": schema.BoolAttribute{
+				Description: "Include the exact command and its full stderr in error messages. By default, values that might be secret, such as public keys and credentials in mirror URLs, are redacted. Can also be set with SOFT_SERVE_VERBOSE_ERRORS. Defaults to false.",
+				Optional:    true,
+			},
+			"protect_last_admin": schema.BoolAttribute{
+				Description: "Refuse to demote a softserve_user from admin when no other user is an admin, which could lock everyone out of the server. Checking lists every user, so it can be turned off for servers with many users. Can also be set with SOFT_SERVE_PROTECT_LAST_ADMIN. Defaults to true.",
 				Optional:    true,
 			},
 		},
@@ -98,9 +227,15 @@ func (p *SoftServeProvider) Configure(ctx context.Context, req provider.Configur
 	// Resolve port
 	port := 23231
 	if envPort := os.Getenv("SOFT_SERVE_PORT"); envPort != "" {
-		if p, err := strconv.Atoi(envPort); err == nil {
-			port = p
+		p, err := strconv.Atoi(envPort)
+		if err != nil || p < 1 || p > 65535 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("port"),
+				"Invalid SOFT_SERVE_PORT",
+				fmt.Sprintf("SOFT_SERVE_PORT must be a TCP port between 1 and 65535, got %q.", envPort),
+			)
 		}
+		port = p
 	}
 	if !config.Port.IsNull() {
 		port = int(config.Port.ValueInt64())
@@ -124,42 +259,255 @@ func (p *SoftServeProvider) Configure(ctx context.Context, req provider.Configur
 	privateKeyPath := ""
 	if !config.PrivateKeyPath.IsNull() {
 		privateKeyPath = config.PrivateKeyPath.ValueString()
-		if strings.HasPrefix(privateKeyPath, "~/") {
-			if home, err := os.UserHomeDir(); err == nil {
-				privateKeyPath = home + privateKeyPath[1:]
-			}
-		}
 	}
+	privateKeyPath = resolveHome(resp, privateKeyPath, "private_key_path")
 
-	// Resolve identity_file
+	// Resolve identity_file and identity_files
+	var identityFiles []string
 	identityFile := os.Getenv("SOFT_SERVE_IDENTITY_FILE")
 	if !config.IdentityFile.IsNull() {
 		identityFile = config.IdentityFile.ValueString()
 	}
-	if strings.HasPrefix(identityFile, "~/") {
-		if home, err := os.UserHomeDir(); err == nil {
-			identityFile = home + identityFile[1:]
+	if identityFile != "" {
+		identityFiles = append(identityFiles, identityFile)
+	}
+	if !config.IdentityFiles.IsNull() {
+		var files []string
+		resp.Diagnostics.Append(config.IdentityFiles.ElementsAs(ctx, &files, false)...)
+		if resp.Diagnostics.HasError() {
+			return
 		}
+		identityFiles = append(identityFiles, files...)
+	}
+	for i, f := range identityFiles {
+		attr := "identity_files"
+		if i == 0 && identityFile != "" {
+			attr = "identity_file"
+		}
+		identityFiles[i] = resolveHome(resp, f, attr)
+	}
+
+	// Resolve identity_fingerprint
+	identityFingerprint := os.Getenv("SOFT_SERVE_IDENTITY_FINGERPRINT")
+	if !config.IdentityFingerprint.IsNull() {
+		identityFingerprint = config.IdentityFingerprint.ValueString()
 	}
 
 	// Resolve use_agent
 	useAgent := true
+	agentExplicit := false
 	if envAgent := os.Getenv("SOFT_SERVE_USE_AGENT"); envAgent != "" {
 		useAgent = envAgent == "true" || envAgent == "1"
+		agentExplicit = true
 	}
 	if !config.UseAgent.IsNull() {
 		useAgent = config.UseAgent.ValueBool()
+		agentExplicit = true
+	}
+
+	// Resolve identities_only
+	identitiesOnly := false
+	if envOnly := os.Getenv("SOFT_SERVE_IDENTITIES_ONLY"); envOnly != "" {
+		identitiesOnly = envOnly == "true" || envOnly == "1"
+	}
+	if !config.IdentitiesOnly.IsNull() {
+		identitiesOnly = config.IdentitiesOnly.ValueBool()
+	}
+
+	// Resolve known_hosts_file
+	knownHostsFile := os.Getenv("SOFT_SERVE_KNOWN_HOSTS_FILE")
+	if !config.KnownHostsFile.IsNull() {
+		knownHostsFile = config.KnownHostsFile.ValueString()
+	}
+	knownHostsFile = resolveHome(resp, knownHostsFile, "known_hosts_file")
+	if knownHostsFile == "" {
+		resp.Diagnostics.AddWarning(
+			"SSH host key verification is disabled",
+			"The provider will accept any host key presented by the Soft Serve server, "+
+				"which leaves connections open to man-in-the-middle attacks. "+
+				"Set known_hosts_file (or SOFT_SERVE_KNOWN_HOSTS_FILE) to a known_hosts file "+
+				"containing the server's host key to enable verification.",
+		)
+	}
+
+	// Resolve command_prefix
+	commandPrefix := os.Getenv("SOFT_SERVE_COMMAND_PREFIX")
+	if !config.CommandPrefix.IsNull() {
+		commandPrefix = config.CommandPrefix.ValueString()
+	}
+
+	// Resolve subsystem
+	subsystem := os.Getenv("SOFT_SERVE_SUBSYSTEM")
+	if !config.Subsystem.IsNull() {
+		subsystem = config.Subsystem.ValueString()
+	}
+
+	// Resolve ssh_env
+	var sshEnv map[string]string
+	if !config.SSHEnv.IsNull() {
+		resp.Diagnostics.Append(config.SSHEnv.ElementsAs(ctx, &sshEnv, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	// Resolve proxy_command
+	proxyCommand := os.Getenv("SOFT_SERVE_PROXY_COMMAND")
+	if !config.ProxyCommand.IsNull() {
+		proxyCommand = config.ProxyCommand.ValueString()
+	}
+
+	// Resolve unix_socket
+	unixSocket := os.Getenv("SOFT_SERVE_UNIX_SOCKET")
+	if !config.UnixSocket.IsNull() {
+		unixSocket = config.UnixSocket.ValueString()
+	}
+	if unixSocket != "" && proxyCommand != "" {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("unix_socket"),
+			"Conflicting connection settings",
+			"unix_socket and proxy_command cannot both be set; choose one way to reach the server.",
+		)
+	}
+
+	// Resolve http_base_url
+	httpBaseURL, source := os.Getenv("SOFT_SERVE_HTTP_BASE_URL"), "SOFT_SERVE_HTTP_BASE_URL"
+	if !config.HTTPBaseURL.IsNull() {
+		httpBaseURL, source = config.HTTPBaseURL.ValueString(), "http_base_url"
+	}
+	if httpBaseURL != "" {
+		if u, err := url.Parse(httpBaseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("http_base_url"),
+				"Invalid HTTP base URL",
+				fmt.Sprintf("%s must be an http or https URL such as \"https://git.example.com\", got %q.", source, httpBaseURL),
+			)
+		}
+	}
+
+	// Resolve verbose_errors
+	verboseErrors := false
+	if envVerbose := os.Getenv("SOFT_SERVE_VERBOSE_ERRORS"); envVerbose != "" {
+		verboseErrors = envVerbose == "true" || envVerbose == "1"
+	}
+	if !config.VerboseErrors.IsNull() {
+		verboseErrors = config.VerboseErrors.ValueBool()
+	}
+
+	// Resolve protect_last_admin
+	protectLastAdmin := true
+	if envProtect := os.Getenv("SOFT_SERVE_PROTECT_LAST_ADMIN"); envProtect != "" {
+		protectLastAdmin = envProtect == "true" || envProtect == "1"
+	}
+	if !config.ProtectLastAdmin.IsNull() {
+		protectLastAdmin = config.ProtectLastAdmin.ValueBool()
+	}
+
+	// Resolve max_retries
+	maxRetries := 3
+	if envRetries := os.Getenv("SOFT_SERVE_MAX_RETRIES"); envRetries != "" {
+		n, err := strconv.Atoi(envRetries)
+		if err != nil || n < 0 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("max_retries"),
+				"Invalid SOFT_SERVE_MAX_RETRIES",
+				fmt.Sprintf("SOFT_SERVE_MAX_RETRIES must be a non-negative integer, got %q.", envRetries),
+			)
+		}
+		maxRetries = n
+	}
+	if !config.MaxRetries.IsNull() {
+		maxRetries = int(config.MaxRetries.ValueInt64())
+	}
+
+	// Resolve retry_base_delay and retry_max_delay
+	retryBaseDelay := resolveDuration(resp, config.RetryBaseDelay, "retry_base_delay", "SOFT_SERVE_RETRY_BASE_DELAY", ssh.DefaultRetryBaseDelay)
+	retryMaxDelay := resolveDuration(resp, config.RetryMaxDelay, "retry_max_delay", "SOFT_SERVE_RETRY_MAX_DELAY", ssh.DefaultRetryMaxDelay)
+
+	// Resolve banner_timeout and auth_timeout
+	bannerTimeout := resolveDuration(resp, config.BannerTimeout, "banner_timeout", "SOFT_SERVE_BANNER_TIMEOUT", ssh.DefaultBannerTimeout)
+	authTimeout := resolveDuration(resp, config.AuthTimeout, "auth_timeout", "SOFT_SERVE_AUTH_TIMEOUT", ssh.DefaultAuthTimeout)
+
+	// Resolve commands_per_second
+	var commandsPerSecond float64
+	if envRate := os.Getenv("SOFT_SERVE_COMMANDS_PER_SECOND"); envRate != "" {
+		r, err := strconv.ParseFloat(envRate, 64)
+		if err != nil || r < 0 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("commands_per_second"),
+				"Invalid SOFT_SERVE_COMMANDS_PER_SECOND",
+				fmt.Sprintf("SOFT_SERVE_COMMANDS_PER_SECOND must be a non-negative number, got %q.", envRate),
+			)
+		}
+		commandsPerSecond = r
+	}
+	if !config.CommandsPerSecond.IsNull() {
+		commandsPerSecond = config.CommandsPerSecond.ValueFloat64()
+	}
+
+	// Resolve max_connections
+	maxConnections := 1
+	if envConns := os.Getenv("SOFT_SERVE_MAX_CONNECTIONS"); envConns != "" {
+		n, err := strconv.Atoi(envConns)
+		if err != nil || n < 1 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("max_connections"),
+				"Invalid SOFT_SERVE_MAX_CONNECTIONS",
+				fmt.Sprintf("SOFT_SERVE_MAX_CONNECTIONS must be a positive integer, got %q.", envConns),
+			)
+		}
+		maxConnections = n
+	}
+	if !config.MaxConnections.IsNull() {
+		maxConnections = int(config.MaxConnections.ValueInt64())
+	}
+
+	// Resolve client_version
+	clientVersion, source := os.Getenv("SOFT_SERVE_CLIENT_VERSION"), "SOFT_SERVE_CLIENT_VERSION"
+	if !config.ClientVersion.IsNull() {
+		clientVersion, source = config.ClientVersion.ValueString(), "client_version"
+	}
+	if clientVersion != "" && !strings.HasPrefix(clientVersion, "SSH-2.0-") {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("client_version"),
+			"Invalid SSH client version",
+			fmt.Sprintf("%s must start with \"SSH-2.0-\", e.g. \"SSH-2.0-terraform\", got %q.", source, clientVersion),
+		)
+	}
+
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
 	// Create SSH client
 	client, err := ssh.NewClient(ssh.ClientConfig{
-		Host:           host,
-		Port:           port,
-		Username:       username,
-		PrivateKey:     privateKey,
-		PrivateKeyPath: privateKeyPath,
-		IdentityFile:   identityFile,
-		UseAgent:       useAgent,
+		Host:                host,
+		Port:                port,
+		Username:            username,
+		PrivateKey:          privateKey,
+		PrivateKeyPath:      privateKeyPath,
+		IdentityFiles:       identityFiles,
+		IdentityFingerprint: identityFingerprint,
+		UseAgent:            useAgent,
+		AgentExplicit:       agentExplicit,
+		IdentitiesOnly:      identitiesOnly,
+		KnownHostsFile:      knownHostsFile,
+		CommandPrefix:       commandPrefix,
+		Subsystem:           subsystem,
+		Env:                 sshEnv,
+		HTTPBaseURL:         httpBaseURL,
+		ProxyCommand:        proxyCommand,
+		UnixSocket:          unixSocket,
+		VerboseErrors:       verboseErrors,
+		ProtectLastAdmin:    protectLastAdmin,
+		MaxRetries:          maxRetries,
+		RetryBaseDelay:      retryBaseDelay,
+		RetryMaxDelay:       retryMaxDelay,
+		BannerTimeout:       bannerTimeout,
+		AuthTimeout:         authTimeout,
+		CommandsPerSecond:   commandsPerSecond,
+		MaxConnections:      maxConnections,
+		ClientVersion:       clientVersion,
 	})
 	if err != nil {
 		resp.Diagnostics.AddAttributeError(
@@ -169,20 +517,152 @@ func (p *SoftServeProvider) Configure(ctx context.Context, req provider.Configur
 		)
 		return
 	}
+	if err := client.AgentError(); err != nil {
+		resp.Diagnostics.AddAttributeWarning(
+			path.Root("use_agent"),
+			"SSH agent unavailable",
+			fmt.Sprintf("use_agent is set, but the SSH agent can't be used, so only the configured keys are offered: %s. Check that SSH_AUTH_SOCK points to a running agent.", err),
+		)
+	}
+
+	// Resolve skip_connection_check
+	skipConnectionCheck := false
+	if envSkip := os.Getenv("SOFT_SERVE_SKIP_CONNECTION_CHECK"); envSkip != "" {
+		skipConnectionCheck = envSkip == "true" || envSkip == "1"
+	}
+	if !config.SkipConnectionCheck.IsNull() {
+		skipConnectionCheck = config.SkipConnectionCheck.ValueBool()
+	}
+
+	if !skipConnectionCheck {
+		if err := client.Ping(ctx); err != nil {
+			_ = client.Close()
+			if errors.Is(err, ssh.ErrUnregisteredKey) {
+				resp.Diagnostics.AddError(
+					"SSH key not registered with Soft Serve",
+					fmt.Sprintf("Connected to %s:%d, but no Soft Serve user has the key the provider authenticated with, so every resource would fail with permission errors: %s\n\n"+
+						"Have an admin register the key with `user add-pubkey`, or, on a new server, list it in SOFT_SERVE_INITIAL_ADMIN_KEYS. Set skip_connection_check = true to configure the provider without checking.",
+						host, port, err),
+				)
+				return
+			}
+			resp.Diagnostics.AddError(
+				"Unable to connect to Soft Serve",
+				fmt.Sprintf("Connecting to %s@%s:%d failed: %s\n\n%s", username, host, port, err, connectionErrorHint(err)),
+			)
+			return
+		}
+	}
 
 	resp.ResourceData = client
 	resp.DataSourceData = client
 }
 
+// connectionErrorHint suggests what to check for a failed connection check.
+func connectionErrorHint(err error) string {
+	var keyErr *knownhosts.KeyError
+	var rsaErr *ssh.RSAKeyRejectedError
+	switch {
+	case errors.As(err, &keyErr) && len(keyErr.Want) > 0:
+		return "The server's host key does not match the one in known_hosts_file. Verify the server's identity before updating the file."
+	case errors.As(err, &keyErr):
+		return "The server's host key is not in known_hosts_file. Add it, for example with ssh-keyscan, after verifying it."
+	case errors.As(err, &rsaErr):
+		return "The server rejected the RSA key. If it is registered with Soft Serve, the server probably refuses ssh-rsa (SHA-1) signatures without offering rsa-sha2-256 or rsa-sha2-512: use an ed25519 or ECDSA key instead, or enable the SHA-2 RSA algorithms on the server."
+	case strings.Contains(err.Error(), "unable to authenticate"):
+		return "The server rejected the credentials. Check username, private_key_path, identity_file, and that the key is registered with Soft Serve."
+	default:
+		return "Check that host and port are correct and the server is running. Set skip_connection_check = true to configure the provider without connecting."
+	}
+}
+
+// expandHome expands a leading "~" or "~user" in p to the home directory of
+// the current or named user, as a shell would. Other paths are returned
+// unchanged.
+func expandHome(p string) (string, error) {
+	if !strings.HasPrefix(p, "~") {
+		return p, nil
+	}
+	name, rest, hasRest := strings.Cut(p[1:], "/")
+
+	var home string
+	if name == "" {
+		h, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		home = h
+	} else {
+		u, err := user.Lookup(name)
+		if err != nil {
+			return "", err
+		}
+		home = u.HomeDir
+	}
+	if !hasRest {
+		return home, nil
+	}
+	return home + "/" + rest, nil
+}
+
+// resolveHome expands a leading "~" in the path given for attr, reporting a
+// failure to find the home directory against attr.
+func resolveHome(resp *provider.ConfigureResponse, p, attr string) string {
+	expanded, err := expandHome(p)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root(attr),
+			"Invalid path",
+			fmt.Sprintf("Expanding %q in %s: %s", p, attr, err),
+		)
+		return p
+	}
+	return expanded
+}
+
+// resolveDuration resolves a duration attribute from its config value, falling
+// back to envVar and then def. Unparseable values are reported against attr.
+func resolveDuration(resp *provider.ConfigureResponse, value types.String, attr, envVar string, def time.Duration) time.Duration {
+	raw, source := os.Getenv(envVar), envVar
+	if !value.IsNull() {
+		raw, source = value.ValueString(), attr
+	}
+	if raw == "" {
+		return def
+	}
+
+	d, err := time.ParseDuration(raw)
+	if err != nil || d < 0 {
+		resp.Diagnostics.AddAttributeError(
+			path.Root(attr),
+			"Invalid duration",
+			fmt.Sprintf("%s must be a non-negative duration such as \"500ms\" or \"2s\", got %q.", source, raw),
+		)
+		return def
+	}
+	return d
+}
+
 func (p *SoftServeProvider) Resources(_ context.Context) []func() resource.Resource {
 	return []func() resource.Resource{
 		softserveresource.NewRepositoryResource,
 		softserveresource.NewUserResource,
 		softserveresource.NewRepositoryCollaboratorResource,
+		softserveresource.NewRepositoryCollaboratorsResource,
+		softserveresource.NewRepositoryBranchResource,
+		softserveresource.NewRepositoryFileResource,
 		softserveresource.NewServerSettingsResource,
 	}
 }
 
 func (p *SoftServeProvider) DataSources(_ context.Context) []func() datasource.DataSource {
-	return nil
+	return []func() datasource.DataSource{
+		softservedatasource.NewRepositoryDataSource,
+		softservedatasource.NewRepositoriesDataSource,
+		softservedatasource.NewSettingsDataSource,
+		softservedatasource.NewRawSettingsDataSource,
+		softservedatasource.NewPubkeyDataSource,
+		softservedatasource.NewRepositoryCollaboratorsDataSource,
+		softservedatasource.NewUserDataSource,
+	}
 }
@@ -2,11 +2,27 @@ package provider
 
 import (
 	"context"
+	"errors"
+	"net"
+	"os/user"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"testing"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+
+	"github.com/ssoriche/terraform-provider-soft-serve/internal/ssh"
+	"github.com/ssoriche/terraform-provider-soft-serve/internal/sshtest"
 )
 
 func TestSoftServeProviderMetadata(t *testing.T) {
@@ -33,7 +49,7 @@ func TestSoftServeProviderSchema(t *testing.T) {
 		t.Fatalf("unexpected errors: %s", resp.Diagnostics)
 	}
 
-	expectedAttrs := []string{"host", "port", "username", "private_key_path", "identity_file", "use_agent"}
+	expectedAttrs := []string{"host", "port", "username", "private_key_path", "identity_file", "identity_files", "identity_fingerprint", "use_agent", "identities_only", "known_hosts_file", "command_prefix", "subsystem", "ssh_env", "proxy_command", "unix_socket", "http_base_url", "max_retries", "retry_base_delay", "retry_max_delay", "banner_timeout", "auth_timeout", "commands_per_second", "max_connections", "client_version", "skip_connection_check", "verbose_errors", "protect_last_admin"}
 	for _, attr := range expectedAttrs {
 		if _, ok := resp.Schema.Attributes[attr]; !ok {
 			t.Errorf("missing expected attribute %q", attr)
@@ -59,7 +75,28 @@ func TestSoftServeProviderSchemaAttributeTypes(t *testing.T) {
 		{"username", "StringAttribute"},
 		{"private_key_path", "StringAttribute"},
 		{"identity_file", "StringAttribute"},
+		{"identity_files", "ListAttribute"},
+		{"identity_fingerprint", "StringAttribute"},
 		{"use_agent", "BoolAttribute"},
+		{"identities_only", "BoolAttribute"},
+		{"known_hosts_file", "StringAttribute"},
+		{"command_prefix", "StringAttribute"},
+		{"subsystem", "StringAttribute"},
+		{"ssh_env", "MapAttribute"},
+		{"proxy_command", "StringAttribute"},
+		{"unix_socket", "StringAttribute"},
+		{"http_base_url", "StringAttribute"},
+		{"max_retries", "Int64Attribute"},
+		{"max_connections", "Int64Attribute"},
+		{"retry_base_delay", "StringAttribute"},
+		{"retry_max_delay", "StringAttribute"},
+		{"banner_timeout", "StringAttribute"},
+		{"auth_timeout", "StringAttribute"},
+		{"commands_per_second", "Float64Attribute"},
+		{"client_version", "StringAttribute"},
+		{"skip_connection_check", "BoolAttribute"},
+		{"verbose_errors", "BoolAttribute"},
+		{"protect_last_admin", "BoolAttribute"},
 	}
 
 	for _, tt := range tests {
@@ -121,7 +158,7 @@ func TestSoftServeProviderResources(t *testing.T) {
 
 	resources := p.Resources(context.Background())
 
-	expectedCount := 4
+	expectedCount := 7
 	if len(resources) != expectedCount {
 		t.Fatalf("got %d resources, want %d", len(resources), expectedCount)
 	}
@@ -139,10 +176,13 @@ func TestSoftServeProviderResourceTypes(t *testing.T) {
 	resources := p.Resources(context.Background())
 
 	expectedTypes := map[string]bool{
-		"softserve_repository":              false,
-		"softserve_user":                    false,
-		"softserve_repository_collaborator": false,
-		"softserve_server_settings":         false,
+		"softserve_repository":               false,
+		"softserve_user":                     false,
+		"softserve_repository_collaborator":  false,
+		"softserve_repository_collaborators": false,
+		"softserve_repository_branch":        false,
+		"softserve_repository_file":          false,
+		"softserve_server_settings":          false,
 	}
 
 	for _, factory := range resources {
@@ -168,8 +208,35 @@ func TestSoftServeProviderDataSources(t *testing.T) {
 
 	dataSources := p.DataSources(context.Background())
 
-	if dataSources != nil {
-		t.Errorf("expected nil data sources, got %d", len(dataSources))
+	expectedTypes := map[string]bool{
+		"softserve_repository":               false,
+		"softserve_repositories":             false,
+		"softserve_settings":                 false,
+		"softserve_raw_settings":             false,
+		"softserve_pubkey":                   false,
+		"softserve_repository_collaborators": false,
+		"softserve_user":                     false,
+	}
+
+	if len(dataSources) != len(expectedTypes) {
+		t.Fatalf("got %d data sources, want %d", len(dataSources), len(expectedTypes))
+	}
+
+	for _, factory := range dataSources {
+		d := factory()
+		metaResp := &datasource.MetadataResponse{}
+		d.Metadata(context.Background(), datasource.MetadataRequest{ProviderTypeName: "softserve"}, metaResp)
+
+		if _, ok := expectedTypes[metaResp.TypeName]; !ok {
+			t.Errorf("unexpected data source type: %q", metaResp.TypeName)
+		}
+		expectedTypes[metaResp.TypeName] = true
+	}
+
+	for typeName, found := range expectedTypes {
+		if !found {
+			t.Errorf("missing expected data source type: %q", typeName)
+		}
 	}
 }
 
@@ -192,3 +259,432 @@ func TestNew(t *testing.T) {
 func TestProviderImplementsInterface(t *testing.T) {
 	var _ provider.Provider = &SoftServeProvider{}
 }
+
+func TestResolveDuration(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   types.String
+		env     string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"default", types.StringNull(), "", time.Second, false},
+		{"from env", types.StringNull(), "750ms", 750 * time.Millisecond, false},
+		{"config overrides env", types.StringValue("2s"), "750ms", 2 * time.Second, false},
+		{"invalid config", types.StringValue("soon"), "", time.Second, true},
+		{"invalid env", types.StringNull(), "-1s", time.Second, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("SOFT_SERVE_TEST_DELAY", tt.env)
+			resp := &provider.ConfigureResponse{}
+
+			got := resolveDuration(resp, tt.value, "test_delay", "SOFT_SERVE_TEST_DELAY", time.Second)
+
+			if resp.Diagnostics.HasError() != tt.wantErr {
+				t.Fatalf("HasError() = %v, want %v: %s", resp.Diagnostics.HasError(), tt.wantErr, resp.Diagnostics)
+			}
+			if got != tt.want {
+				t.Errorf("resolveDuration() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandHome(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	current, err := user.Current()
+	if err != nil {
+		t.Skipf("current user unknown: %s", err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{"home", "~", home, false},
+		{"under home", "~/.ssh/id_ed25519", home + "/.ssh/id_ed25519", false},
+		{"named user", "~" + current.Username + "/.ssh/known_hosts", current.HomeDir + "/.ssh/known_hosts", false},
+		{"named user only", "~" + current.Username, current.HomeDir, false},
+		{"unknown user", "~no-such-user-soft-serve/.ssh/id_rsa", "", true},
+		{"absolute", "/etc/ssh/ssh_known_hosts", "/etc/ssh/ssh_known_hosts", false},
+		{"relative", "keys/id_rsa", "keys/id_rsa", false},
+		{"tilde inside", "/tmp/~/id_rsa", "/tmp/~/id_rsa", false},
+		{"empty", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := expandHome(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expandHome(%q) error = %v, wantErr %v", tt.path, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("expandHome(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+// emptyConfig returns a provider configuration with every attribute unset.
+func emptyConfig(t *testing.T, p *SoftServeProvider) tfsdk.Config {
+	t.Helper()
+	ctx := context.Background()
+
+	schemaResp := &provider.SchemaResponse{}
+	p.Schema(ctx, provider.SchemaRequest{}, schemaResp)
+
+	objType, ok := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object)
+	if !ok {
+		t.Fatal("provider schema is not an object type")
+	}
+	attrs := make(map[string]tftypes.Value, len(objType.AttributeTypes))
+	for name, typ := range objType.AttributeTypes {
+		attrs[name] = tftypes.NewValue(typ, nil)
+	}
+	return tfsdk.Config{Schema: schemaResp.Schema, Raw: tftypes.NewValue(objType, attrs)}
+}
+
+func TestConfigure_EnvPort(t *testing.T) {
+	tests := []struct {
+		env     string
+		wantErr bool
+	}{
+		{"", false},
+		{"2222", false},
+		{"65535", false},
+		{"0", true},
+		{"2323100", true},
+		{"-22", true},
+		{"ssh", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.env, func(t *testing.T) {
+			t.Setenv("SOFT_SERVE_PORT", tt.env)
+			t.Setenv("SOFT_SERVE_PRIVATE_KEY", sshtest.PrivateKey(t))
+			t.Setenv("SOFT_SERVE_USE_AGENT", "false")
+			t.Setenv("SOFT_SERVE_SKIP_CONNECTION_CHECK", "true")
+			p := &SoftServeProvider{}
+			resp := &provider.ConfigureResponse{}
+
+			p.Configure(context.Background(), provider.ConfigureRequest{Config: emptyConfig(t, p)}, resp)
+
+			if !tt.wantErr {
+				if resp.Diagnostics.HasError() {
+					t.Fatalf("unexpected errors: %s", resp.Diagnostics)
+				}
+				if resp.ResourceData == nil {
+					t.Error("client should be configured")
+				}
+				return
+			}
+
+			var found bool
+			for _, d := range resp.Diagnostics.Errors() {
+				if d.Summary() == "Invalid SOFT_SERVE_PORT" {
+					found = true
+					if pd, ok := d.(diag.DiagnosticWithPath); !ok || !pd.Path().Equal(path.Root("port")) {
+						t.Errorf("diagnostic not attributed to port: %v", d)
+					}
+				}
+			}
+			if !found {
+				t.Errorf("missing Invalid SOFT_SERVE_PORT diagnostic: %s", resp.Diagnostics)
+			}
+			if resp.ResourceData != nil {
+				t.Error("client should not be configured when the port is invalid")
+			}
+		})
+	}
+}
+
+func TestConfigure_EnvCommandsPerSecond(t *testing.T) {
+	tests := []struct {
+		env     string
+		wantErr bool
+	}{
+		{"", false},
+		{"0", false},
+		{"0.5", false},
+		{"10", false},
+		{"-1", true},
+		{"fast", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.env, func(t *testing.T) {
+			t.Setenv("SOFT_SERVE_COMMANDS_PER_SECOND", tt.env)
+			t.Setenv("SOFT_SERVE_PRIVATE_KEY", sshtest.PrivateKey(t))
+			t.Setenv("SOFT_SERVE_USE_AGENT", "false")
+			t.Setenv("SOFT_SERVE_SKIP_CONNECTION_CHECK", "true")
+			p := &SoftServeProvider{}
+			resp := &provider.ConfigureResponse{}
+
+			p.Configure(context.Background(), provider.ConfigureRequest{Config: emptyConfig(t, p)}, resp)
+
+			if resp.Diagnostics.HasError() != tt.wantErr {
+				t.Fatalf("HasError() = %v, want %v: %s", resp.Diagnostics.HasError(), tt.wantErr, resp.Diagnostics)
+			}
+			if tt.wantErr {
+				if got := resp.Diagnostics.Errors()[0].Summary(); got != "Invalid SOFT_SERVE_COMMANDS_PER_SECOND" {
+					t.Errorf("summary = %q, want %q", got, "Invalid SOFT_SERVE_COMMANDS_PER_SECOND")
+				}
+				return
+			}
+			if client, ok := resp.ResourceData.(*ssh.Client); ok {
+				t.Cleanup(func() { _ = client.Close() })
+			}
+		})
+	}
+}
+
+func TestConfigure_EnvClientVersion(t *testing.T) {
+	tests := []struct {
+		env     string
+		wantErr bool
+	}{
+		{"", false},
+		{"SSH-2.0-terraform", false},
+		{"terraform", true},
+		{"SSH-1.99-terraform", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.env, func(t *testing.T) {
+			t.Setenv("SOFT_SERVE_CLIENT_VERSION", tt.env)
+			t.Setenv("SOFT_SERVE_PRIVATE_KEY", sshtest.PrivateKey(t))
+			t.Setenv("SOFT_SERVE_USE_AGENT", "false")
+			t.Setenv("SOFT_SERVE_SKIP_CONNECTION_CHECK", "true")
+			p := &SoftServeProvider{}
+			resp := &provider.ConfigureResponse{}
+
+			p.Configure(context.Background(), provider.ConfigureRequest{Config: emptyConfig(t, p)}, resp)
+
+			if resp.Diagnostics.HasError() != tt.wantErr {
+				t.Fatalf("HasError() = %v, want %v: %s", resp.Diagnostics.HasError(), tt.wantErr, resp.Diagnostics)
+			}
+			if tt.wantErr {
+				if got := resp.Diagnostics.Errors()[0].Summary(); got != "Invalid SSH client version" {
+					t.Errorf("summary = %q, want %q", got, "Invalid SSH client version")
+				}
+				return
+			}
+			if client, ok := resp.ResourceData.(*ssh.Client); ok {
+				t.Cleanup(func() { _ = client.Close() })
+			}
+		})
+	}
+}
+
+func TestConfigure_AgentSocketUnavailableWarns(t *testing.T) {
+	t.Setenv("SSH_AUTH_SOCK", filepath.Join(t.TempDir(), "missing.sock"))
+	t.Setenv("SOFT_SERVE_PRIVATE_KEY", sshtest.PrivateKey(t))
+	t.Setenv("SOFT_SERVE_USE_AGENT", "true")
+	t.Setenv("SOFT_SERVE_SKIP_CONNECTION_CHECK", "true")
+	p := &SoftServeProvider{}
+	resp := &provider.ConfigureResponse{}
+
+	p.Configure(context.Background(), provider.ConfigureRequest{Config: emptyConfig(t, p)}, resp)
+	if client, ok := resp.ResourceData.(*ssh.Client); ok {
+		t.Cleanup(func() { _ = client.Close() })
+	}
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Configure() errors: %s", resp.Diagnostics)
+	}
+	var found bool
+	for _, w := range resp.Diagnostics.Warnings() {
+		found = found || w.Summary() == "SSH agent unavailable"
+	}
+	if !found {
+		t.Errorf("warnings = %s, want SSH agent unavailable", resp.Diagnostics.Warnings())
+	}
+}
+
+func TestConfigure_EnvHTTPBaseURL(t *testing.T) {
+	tests := []struct {
+		env     string
+		want    string
+		wantErr bool
+	}{
+		{"", "", false},
+		{"https://git.example.com", "https://git.example.com/myrepo.git", false},
+		{"http://localhost:23232/", "http://localhost:23232/myrepo.git", false},
+		{"git.example.com", "", true},
+		{"ftp://git.example.com", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.env, func(t *testing.T) {
+			t.Setenv("SOFT_SERVE_HTTP_BASE_URL", tt.env)
+			t.Setenv("SOFT_SERVE_PRIVATE_KEY", sshtest.PrivateKey(t))
+			t.Setenv("SOFT_SERVE_USE_AGENT", "false")
+			t.Setenv("SOFT_SERVE_SKIP_CONNECTION_CHECK", "true")
+			p := &SoftServeProvider{}
+			resp := &provider.ConfigureResponse{}
+
+			p.Configure(context.Background(), provider.ConfigureRequest{Config: emptyConfig(t, p)}, resp)
+
+			if resp.Diagnostics.HasError() != tt.wantErr {
+				t.Fatalf("HasError() = %v, want %v: %s", resp.Diagnostics.HasError(), tt.wantErr, resp.Diagnostics)
+			}
+			if tt.wantErr {
+				if got := resp.Diagnostics.Errors()[0].Summary(); got != "Invalid HTTP base URL" {
+					t.Errorf("summary = %q, want %q", got, "Invalid HTTP base URL")
+				}
+				return
+			}
+			client, ok := resp.ResourceData.(*ssh.Client)
+			if !ok {
+				t.Fatalf("ResourceData = %T, want *ssh.Client", resp.ResourceData)
+			}
+			t.Cleanup(func() { _ = client.Close() })
+			if got := client.HTTPCloneURL("myrepo"); got != tt.want {
+				t.Errorf("HTTPCloneURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigure_ConnectionCheck(t *testing.T) {
+	srv := sshtest.NewServer(t, func(string) (string, error) { return "Username: admin", nil })
+	anonSrv := sshtest.NewServer(t, func(string) (string, error) { return "", errors.New("user not found") })
+
+	tests := []struct {
+		name        string
+		port        int
+		skip        string
+		wantSummary string // empty for no error
+	}{
+		{"reachable", srv.Port(), "", ""},
+		{"unreachable", closedPort(t), "", "Unable to connect to Soft Serve"},
+		{"unreachable but skipped", closedPort(t), "true", ""},
+		{"unregistered key", anonSrv.Port(), "", "SSH key not registered with Soft Serve"},
+		{"unregistered key but skipped", anonSrv.Port(), "true", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("SOFT_SERVE_HOST", srv.Host())
+			t.Setenv("SOFT_SERVE_PORT", strconv.Itoa(tt.port))
+			t.Setenv("SOFT_SERVE_PRIVATE_KEY", sshtest.PrivateKey(t))
+			t.Setenv("SOFT_SERVE_USE_AGENT", "false")
+			t.Setenv("SOFT_SERVE_MAX_RETRIES", "0")
+			t.Setenv("SOFT_SERVE_SKIP_CONNECTION_CHECK", tt.skip)
+			p := &SoftServeProvider{}
+			resp := &provider.ConfigureResponse{}
+
+			p.Configure(context.Background(), provider.ConfigureRequest{Config: emptyConfig(t, p)}, resp)
+
+			if wantErr := tt.wantSummary != ""; resp.Diagnostics.HasError() != wantErr {
+				t.Fatalf("HasError() = %v, want %v: %s", resp.Diagnostics.HasError(), wantErr, resp.Diagnostics)
+			}
+			if tt.wantSummary != "" {
+				if got := resp.Diagnostics.Errors()[0].Summary(); got != tt.wantSummary {
+					t.Errorf("summary = %q, want %q", got, tt.wantSummary)
+				}
+				return
+			}
+			client, ok := resp.ResourceData.(*ssh.Client)
+			if !ok {
+				t.Fatalf("ResourceData = %T, want *ssh.Client", resp.ResourceData)
+			}
+			_ = client.Close()
+		})
+	}
+}
+
+// TestConfigure_ProviderInstancesAreIsolated guards multi-server setups, where
+// the provider is configured once per alias: each instance must get its own
+// client and connection rather than sharing one.
+func TestConfigure_ProviderInstancesAreIsolated(t *testing.T) {
+	t.Setenv("SOFT_SERVE_PRIVATE_KEY", sshtest.PrivateKey(t))
+	t.Setenv("SOFT_SERVE_USE_AGENT", "false")
+
+	configure := func(srv *sshtest.Server) *ssh.Client {
+		t.Helper()
+		t.Setenv("SOFT_SERVE_HOST", srv.Host())
+		t.Setenv("SOFT_SERVE_PORT", strconv.Itoa(srv.Port()))
+		p, ok := New("test")().(*SoftServeProvider)
+		if !ok {
+			t.Fatal("New() did not return a *SoftServeProvider")
+		}
+		resp := &provider.ConfigureResponse{}
+		p.Configure(context.Background(), provider.ConfigureRequest{Config: emptyConfig(t, p)}, resp)
+		if resp.Diagnostics.HasError() {
+			t.Fatalf("Configure() errors: %s", resp.Diagnostics)
+		}
+		client, ok := resp.ResourceData.(*ssh.Client)
+		if !ok {
+			t.Fatalf("ResourceData = %T, want *ssh.Client", resp.ResourceData)
+		}
+		t.Cleanup(func() { _ = client.Close() })
+		return client
+	}
+	handler := func(string) (string, error) { return "Username: admin", nil }
+	primarySrv, secondarySrv := sshtest.NewServer(t, handler), sshtest.NewServer(t, handler)
+	primary, secondary := configure(primarySrv), configure(secondarySrv)
+
+	if primary == secondary {
+		t.Fatal("both provider instances got the same client")
+	}
+	if _, err := primary.Run(context.Background(), "repo list"); err != nil {
+		t.Fatalf("primary Run() error = %v", err)
+	}
+	if _, err := secondary.Run(context.Background(), "user list"); err != nil {
+		t.Fatalf("secondary Run() error = %v", err)
+	}
+
+	for _, tt := range []struct {
+		name string
+		srv  *sshtest.Server
+		want []string
+	}{
+		{"primary", primarySrv, []string{"info", "repo list"}},
+		{"secondary", secondarySrv, []string{"info", "user list"}},
+	} {
+		if got := tt.srv.Commands(); !slices.Equal(got, tt.want) {
+			t.Errorf("%s server commands = %q, want %q", tt.name, got, tt.want)
+		}
+		if got := tt.srv.Connections(); got != 1 {
+			t.Errorf("%s server accepted %d connections, want 1", tt.name, got)
+		}
+	}
+}
+
+// closedPort returns a loopback port with nothing listening on it.
+func closedPort(t *testing.T) int {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("loopback listener unavailable: %v", err)
+	}
+	port := l.Addr().(*net.TCPAddr).Port
+	_ = l.Close()
+	return port
+}
+
+func TestConfigure_UnixSocketConflictsWithProxyCommand(t *testing.T) {
+	t.Setenv("SOFT_SERVE_UNIX_SOCKET", "/run/soft-serve/ssh.sock")
+	t.Setenv("SOFT_SERVE_PROXY_COMMAND", "nc %h %p")
+	t.Setenv("SOFT_SERVE_PRIVATE_KEY", sshtest.PrivateKey(t))
+	t.Setenv("SOFT_SERVE_USE_AGENT", "false")
+	t.Setenv("SOFT_SERVE_SKIP_CONNECTION_CHECK", "true")
+	p := &SoftServeProvider{}
+	resp := &provider.ConfigureResponse{}
+
+	p.Configure(context.Background(), provider.ConfigureRequest{Config: emptyConfig(t, p)}, resp)
+
+	if !resp.Diagnostics.HasError() {
+		t.Fatal("expected an error when both unix_socket and proxy_command are set")
+	}
+	if got := resp.Diagnostics.Errors()[0].Summary(); got != "Conflicting connection settings" {
+		t.Errorf("summary = %q, want %q", got, "Conflicting connection settings")
+	}
+}
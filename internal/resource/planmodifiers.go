@@ -0,0 +1,144 @@
+package resource
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+
+	"github.com/ssoriche/terraform-provider-soft-serve/internal/ssh"
+)
+
+// EquivalentAccessLevelModifier keeps the prior state's access level in the
+// plan when it means the same as the planned one, so that an empty level
+// reported by the server, which it treats as read-write, or a differently
+// spelled level doesn't show as a change.
+func EquivalentAccessLevelModifier() planmodifier.String {
+	return equivalentAccessLevelModifier{}
+}
+
+type equivalentAccessLevelModifier struct{}
+
+func (m equivalentAccessLevelModifier) Description(_ context.Context) string {
+	return "Treats equivalent access levels, including an empty one and read-write, as unchanged."
+}
+
+func (m equivalentAccessLevelModifier) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+func (m equivalentAccessLevelModifier) PlanModifyString(_ context.Context, req planmodifier.StringRequest, resp *planmodifier.StringResponse) {
+	if req.StateValue.IsNull() || req.PlanValue.IsNull() || req.PlanValue.IsUnknown() {
+		return
+	}
+	if sameAccessLevel(req.StateValue.ValueString(), req.PlanValue.ValueString()) {
+		resp.PlanValue = req.StateValue
+	}
+}
+
+// NullWhenEmptyModifier plans an Optional+Computed string that is unset in
+// the configuration as its prior value when that is null or empty, instead of
+// leaving it unknown. Together with keeping such a value null on read while
+// the server reports it empty, this stops plans from flipping between null
+// and "".
+func NullWhenEmptyModifier() planmodifier.String {
+	return nullWhenEmptyModifier{}
+}
+
+type nullWhenEmptyModifier struct{}
+
+func (m nullWhenEmptyModifier) Description(_ context.Context) string {
+	return "Keeps an unset value null, or empty, while the server reports it empty."
+}
+
+func (m nullWhenEmptyModifier) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+func (m nullWhenEmptyModifier) PlanModifyString(_ context.Context, req planmodifier.StringRequest, resp *planmodifier.StringResponse) {
+	if !req.ConfigValue.IsNull() {
+		return
+	}
+	if req.StateValue.IsNull() || req.StateValue.ValueString() == "" {
+		resp.PlanValue = req.StateValue
+	}
+}
+
+// UseNullStateForUnknownModifier plans a Computed string as its prior value
+// when that is null, once the resource exists. UseStateForUnknown leaves a
+// null prior value unknown, so a value the server never reports would
+// otherwise show as "known after apply" in every plan.
+func UseNullStateForUnknownModifier() planmodifier.String {
+	return useNullStateForUnknownModifier{}
+}
+
+type useNullStateForUnknownModifier struct{}
+
+func (m useNullStateForUnknownModifier) Description(_ context.Context) string {
+	return "Keeps a value the server hasn't reported null."
+}
+
+func (m useNullStateForUnknownModifier) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+func (m useNullStateForUnknownModifier) PlanModifyString(_ context.Context, req planmodifier.StringRequest, resp *planmodifier.StringResponse) {
+	if req.State.Raw.IsNull() || !req.PlanValue.IsUnknown() {
+		return
+	}
+	if req.StateValue.IsNull() {
+		resp.PlanValue = req.StateValue
+	}
+}
+
+// UseStateUnlessChangedModifier plans a Computed string as its prior value
+// unless one of the attributes at paths is planned to change, such as a
+// timestamp the server only updates along with them. Other changes, e.g. to
+// collaborators, then don't show it as "known after apply".
+func UseStateUnlessChangedModifier(paths ...path.Path) planmodifier.String {
+	return useStateUnlessChangedModifier{paths: paths}
+}
+
+type useStateUnlessChangedModifier struct {
+	paths []path.Path
+}
+
+func (m useStateUnlessChangedModifier) Description(_ context.Context) string {
+	return "Keeps the prior value unless an attribute it depends on changes."
+}
+
+func (m useStateUnlessChangedModifier) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+func (m useStateUnlessChangedModifier) PlanModifyString(ctx context.Context, req planmodifier.StringRequest, resp *planmodifier.StringResponse) {
+	if req.State.Raw.IsNull() || req.Plan.Raw.IsNull() || !req.PlanValue.IsUnknown() {
+		return
+	}
+	for _, p := range m.paths {
+		var planned, prior attr.Value
+		resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, p, &planned)...)
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, p, &prior)...)
+		if resp.Diagnostics.HasError() || !planned.Equal(prior) {
+			return
+		}
+	}
+	resp.PlanValue = req.StateValue
+}
+
+// sameAccessLevel reports whether access levels a and b are equivalent. An
+// empty level is read-write, the level Soft Serve gives collaborators added
+// without one.
+func sameAccessLevel(a, b string) bool {
+	normalize := func(s string) ssh.AccessLevel {
+		if s == "" {
+			return ssh.AccessLevelReadWrite
+		}
+		if l, ok := ssh.NormalizeAccessLevel(s); ok {
+			return l
+		}
+		return ssh.AccessLevel(s)
+	}
+	return normalize(a) == normalize(b)
+}
@@ -2,37 +2,80 @@ package resource
 
 import (
 	"context"
+	"errors"
 	"fmt"
-
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/resourcevalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 
 	"github.com/ssoriche/terraform-provider-soft-serve/internal/ssh"
 )
 
 var (
-	_ resource.Resource                = &RepositoryResource{}
-	_ resource.ResourceWithImportState = &RepositoryResource{}
+	_ resource.Resource                     = &RepositoryResource{}
+	_ resource.ResourceWithImportState      = &RepositoryResource{}
+	_ resource.ResourceWithConfigValidators = &RepositoryResource{}
 )
 
+// repoInfoAttempts bounds how many times repoInfo reads incomplete repository
+// info before giving up, waiting repoInfoRetryDelay between reads.
+const repoInfoAttempts = 5
+
+var repoInfoRetryDelay = 200 * time.Millisecond
+
 type RepositoryResource struct {
 	client *ssh.Client
 }
 
 type RepositoryResourceModel struct {
-	ID          types.String `tfsdk:"id"`
-	Name        types.String `tfsdk:"name"`
-	Description types.String `tfsdk:"description"`
-	ProjectName types.String `tfsdk:"project_name"`
-	Private     types.Bool   `tfsdk:"private"`
-	Hidden      types.Bool   `tfsdk:"hidden"`
+	ID             types.String `tfsdk:"id"`
+	Name           types.String `tfsdk:"name"`
+	Description    types.String `tfsdk:"description"`
+	ProjectName    types.String `tfsdk:"project_name"`
+	Private        types.Bool   `tfsdk:"private"`
+	Hidden         types.Bool   `tfsdk:"hidden"`
+	MirrorURL      types.String `tfsdk:"mirror_url"`
+	MirrorUsername types.String `tfsdk:"mirror_username"`
+	MirrorPassword types.String `tfsdk:"mirror_password"`
+	SourcePath     types.String `tfsdk:"source_path"`
+	UpstreamURL    types.String `tfsdk:"upstream_url"`
+	InitialBranch  types.String `tfsdk:"initial_branch"`
+	DefaultBranch  types.String `tfsdk:"default_branch"`
+	IsEmpty        types.Bool   `tfsdk:"is_empty"`
+	ForceDestroy   types.Bool   `tfsdk:"force_destroy"`
+	SSHCloneURL    types.String `tfsdk:"ssh_clone_url"`
+	HTTPCloneURL   types.String `tfsdk:"http_clone_url"`
+	CreatedAt      types.String `tfsdk:"created_at"`
+	UpdatedAt      types.String `tfsdk:"updated_at"`
+	Collaborators  types.Set    `tfsdk:"collaborator"`
 }
 
+// InlineCollaboratorModel is a collaborator block of softserve_repository.
+type InlineCollaboratorModel struct {
+	Username    types.String `tfsdk:"username"`
+	AccessLevel types.String `tfsdk:"access_level"`
+}
+
+var inlineCollaboratorType = types.ObjectType{AttrTypes: map[string]attr.Type{
+	"username":     types.StringType,
+	"access_level": types.StringType,
+}}
+
 func NewRepositoryResource() resource.Resource {
 	return &RepositoryResource{}
 }
@@ -46,7 +89,7 @@ func (r *RepositoryResource) Schema(_ context.Context, _ resource.SchemaRequest,
 		Description: "Manages a Soft Serve git repository.",
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
-				Description: "Repository identifier (same as name).",
+				Description: "Repository identifier: the name as the server reports it, which may differ from name in case.",
 				Computed:    true,
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.UseStateForUnknown(),
@@ -60,28 +103,167 @@ func (r *RepositoryResource) Schema(_ context.Context, _ resource.SchemaRequest,
 				},
 			},
 			"description": schema.StringAttribute{
-				Description: "Repository description.",
+				Description: fmt.Sprintf("Repository description, at most %d characters.", MaxDescriptionLength),
 				Optional:    true,
 				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					NullWhenEmptyModifier(),
+				},
+				Validators: []validator.String{
+					stringvalidator.LengthAtMost(MaxDescriptionLength),
+				},
 			},
 			"project_name": schema.StringAttribute{
-				Description: "Project name for the repository.",
+				Description: fmt.Sprintf("Project name for the repository, at most %d characters. Leading and trailing whitespace is trimmed on the server and ignored when comparing.", MaxProjectNameLength),
 				Optional:    true,
 				Computed:    true,
+				Validators: []validator.String{
+					stringvalidator.LengthAtMost(MaxProjectNameLength),
+				},
 			},
 			"private": schema.BoolAttribute{
-				Description: "Whether the repository is private.",
+				Description: "Whether the repository is private. Set when the repository is created, or right after it on servers whose `repo create` doesn't support the visibility flags.",
 				Optional:    true,
 				Computed:    true,
 				Default:     booldefault.StaticBool(false),
 			},
 			"hidden": schema.BoolAttribute{
-				Description: "Whether the repository is hidden.",
+				Description: "Whether the repository is hidden. Set when the repository is created, or right after it on servers whose `repo create` doesn't support the visibility flags.",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+			},
+			"mirror_url": schema.StringAttribute{
+				Description: "URL of an upstream repository to mirror. When set, the repository is created by importing it as a mirror. Use mirror_username and mirror_password rather than embedding credentials here, which would show them in plans.",
+				Optional:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"mirror_username": schema.StringAttribute{
+				Description: "Username for a private mirror_url. The credentials are only used when importing, so changing them forces a new repository.",
+				Optional:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					stringvalidator.AlsoRequires(path.MatchRoot("mirror_url")),
+				},
+			},
+			"mirror_password": schema.StringAttribute{
+				Description: "Password or access token for a private mirror_url.",
+				Optional:    true,
+				Sensitive:   true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					stringvalidator.AlsoRequires(path.MatchRoot("mirror_username")),
+				},
+			},
+			"source_path": schema.StringAttribute{
+				Description: "Path, on the Soft Serve server, of an existing repository to import, such as a bare repository being migrated onto the server. The repository is copied once when created and isn't a mirror; changing this forces a new repository. Recent Soft Serve releases refuse to import local paths.",
+				Optional:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"upstream_url": schema.StringAttribute{
+				Description: "URL the mirror pulls from as reported by the server, without any credentials. Null for repositories that aren't mirrors, or when the server doesn't report it.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"initial_branch": schema.StringAttribute{
+				Description: "Name of the default branch to create the repository with. Changing this forces a new repository. When unset, the server default is used.",
+				Optional:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					stringvalidator.ConflictsWith(path.MatchRoot("mirror_url"), path.MatchRoot("source_path")),
+				},
+			},
+			"default_branch": schema.StringAttribute{
+				Description: "Current default branch of the repository. Null while the server doesn't report one.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+					UseNullStateForUnknownModifier(),
+				},
+			},
+			"is_empty": schema.BoolAttribute{
+				Description: "Whether the repository has no commits yet, i.e. no branches.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"force_destroy": schema.BoolAttribute{
+				Description: "Whether to delete the repository even if it has branches other than the default branch, or any tags. Must be applied before the destroy to take effect.",
 				Optional:    true,
 				Computed:    true,
 				Default:     booldefault.StaticBool(false),
 			},
+			"ssh_clone_url": schema.StringAttribute{
+				Description: "URL for cloning the repository over SSH.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"http_clone_url": schema.StringAttribute{
+				Description: "URL for cloning the repository over HTTP. Null unless the provider's http_base_url is set.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"created_at": schema.StringAttribute{
+				Description: "When the repository was created, in RFC3339 format. Null when the server doesn't report it.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"updated_at": schema.StringAttribute{
+				Description: "When the repository was last updated, in RFC3339 format. Null when the server doesn't report it.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					UseStateUnlessChangedModifier(path.Root("description"), path.Root("project_name"), path.Root("private"), path.Root("hidden")),
+				},
+			},
 		},
+		Blocks: map[string]schema.Block{
+			"collaborator": schema.SetNestedBlock{
+				Description: "A collaborator on the repository. Only the collaborators listed here are managed, so others can be added with softserve_repository_collaborator; don't list a user both here and in a separate resource, or combine these blocks with softserve_repository_collaborators.",
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"username": schema.StringAttribute{
+							Description: "Username of the collaborator.",
+							Required:    true,
+						},
+						"access_level": schema.StringAttribute{
+							Description: "Access level: no-access, read-only, read-write, or admin-access. Defaults to read-write.",
+							Optional:    true,
+							Validators: []validator.String{
+								AccessLevelValidator(),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (r *RepositoryResource) ConfigValidators(_ context.Context) []resource.ConfigValidator {
+	return []resource.ConfigValidator{
+		resourcevalidator.Conflicting(path.MatchRoot("mirror_url"), path.MatchRoot("source_path")),
 	}
 }
 
@@ -108,6 +290,7 @@ func (r *RepositoryResource) Create(ctx context.Context, req resource.CreateRequ
 	name := plan.Name.ValueString()
 	opts := ssh.RepoCreateOpts{
 		Private: plan.Private.ValueBool(),
+		Hidden:  plan.Hidden.ValueBool(),
 	}
 	if !plan.Description.IsNull() && !plan.Description.IsUnknown() {
 		opts.Description = plan.Description.ValueString()
@@ -115,21 +298,66 @@ func (r *RepositoryResource) Create(ctx context.Context, req resource.CreateRequ
 	if !plan.ProjectName.IsNull() && !plan.ProjectName.IsUnknown() {
 		opts.ProjectName = plan.ProjectName.ValueString()
 	}
+	if !plan.InitialBranch.IsNull() && !plan.InitialBranch.IsUnknown() {
+		opts.InitialBranch = plan.InitialBranch.ValueString()
+	}
 
-	if err := r.client.RepoCreate(name, opts); err != nil {
-		resp.Diagnostics.AddError("Error creating repository", err.Error())
-		return
+	// Mirrors and repositories copied from a path on the server are imported
+	importOpts := ssh.RepoImportOpts{
+		Description: opts.Description,
+		ProjectName: opts.ProjectName,
+	}
+	var remote string
+	switch {
+	case !plan.MirrorURL.IsNull() && !plan.MirrorURL.IsUnknown():
+		remote = plan.MirrorURL.ValueString()
+		importOpts.Mirror = true
+		importOpts.Username = plan.MirrorUsername.ValueString()
+		importOpts.Password = plan.MirrorPassword.ValueString()
+	case !plan.SourcePath.IsNull() && !plan.SourcePath.IsUnknown():
+		remote = plan.SourcePath.ValueString()
 	}
 
-	// Set hidden after creation if needed
-	if plan.Hidden.ValueBool() {
-		if err := r.client.RepoSetHidden(name, true); err != nil {
-			resp.Diagnostics.AddError("Error setting repository hidden", err.Error())
+	if remote != "" {
+		if err := r.client.RepoImport(ctx, name, remote, importOpts); err != nil {
+			resp.Diagnostics.AddError("Error importing repository", err.Error())
 			return
 		}
+
+		// repo import doesn't take the visibility flags, so set them afterwards
+		if opts.Private {
+			if err := r.client.RepoSetPrivate(ctx, name, true); err != nil {
+				resp.Diagnostics.AddError("Error setting repository private", err.Error())
+				r.saveCreated(ctx, name, &plan, resp)
+				return
+			}
+		}
+		if opts.Hidden {
+			if err := r.client.RepoSetHidden(ctx, name, true); err != nil {
+				resp.Diagnostics.AddError("Error setting repository hidden", err.Error())
+				r.saveCreated(ctx, name, &plan, resp)
+				return
+			}
+		}
+	} else if err := r.client.RepoCreate(ctx, name, opts); err != nil {
+		if !ssh.IsAlreadyExists(err) {
+			resp.Diagnostics.AddError("Error creating repository", err.Error())
+			return
+		}
+		resp.Diagnostics.Append(r.adoptRacedRepo(ctx, name, &plan, err)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	resp.Diagnostics.Append(r.applyCollaborators(ctx, name, types.SetNull(inlineCollaboratorType), plan.Collaborators)...)
+	if resp.Diagnostics.HasError() {
+		r.saveCreated(ctx, name, &plan, resp)
+		return
 	}
 
-	resp.Diagnostics.Append(r.readRepoState(name, &plan)...)
+	resp.Diagnostics.Append(r.readRepoState(ctx, name, &plan)...)
+	resp.Diagnostics.Append(r.readCollaborators(ctx, name, &plan)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
@@ -137,6 +365,21 @@ func (r *RepositoryResource) Create(ctx context.Context, req resource.CreateRequ
 	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
 }
 
+// saveCreated records a repository that Create made before a later step
+// failed. With the error already in resp, Terraform marks the saved resource
+// tainted and replaces it on the next apply, rather than leaving the
+// repository orphaned and failing to create it again.
+func (r *RepositoryResource) saveCreated(ctx context.Context, name string, plan *RepositoryResourceModel, resp *resource.CreateResponse) {
+	var diags diag.Diagnostics
+	diags.Append(r.readRepoState(ctx, name, plan)...)
+	diags.Append(r.readCollaborators(ctx, name, plan)...)
+	resp.Diagnostics.Append(diags...)
+	if diags.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+}
+
 func (r *RepositoryResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
 	var state RepositoryResourceModel
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
@@ -144,7 +387,8 @@ func (r *RepositoryResource) Read(ctx context.Context, req resource.ReadRequest,
 		return
 	}
 
-	resp.Diagnostics.Append(r.readRepoState(state.Name.ValueString(), &state)...)
+	resp.Diagnostics.Append(r.readRepoState(ctx, state.Name.ValueString(), &state)...)
+	resp.Diagnostics.Append(r.readCollaborators(ctx, state.Name.ValueString(), &state)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
@@ -167,7 +411,7 @@ func (r *RepositoryResource) Update(ctx context.Context, req resource.UpdateRequ
 		if !plan.Description.IsNull() {
 			desc = plan.Description.ValueString()
 		}
-		if err := r.client.RepoSetDescription(name, desc); err != nil {
+		if err := r.client.RepoSetDescription(ctx, name, desc); err != nil {
 			resp.Diagnostics.AddError("Error updating description", err.Error())
 			return
 		}
@@ -178,27 +422,33 @@ func (r *RepositoryResource) Update(ctx context.Context, req resource.UpdateRequ
 		if !plan.ProjectName.IsNull() {
 			pn = plan.ProjectName.ValueString()
 		}
-		if err := r.client.RepoSetProjectName(name, pn); err != nil {
+		if err := r.client.RepoSetProjectName(ctx, name, pn); err != nil {
 			resp.Diagnostics.AddError("Error updating project name", err.Error())
 			return
 		}
 	}
 
 	if !plan.Private.Equal(state.Private) {
-		if err := r.client.RepoSetPrivate(name, plan.Private.ValueBool()); err != nil {
+		if err := r.client.RepoSetPrivate(ctx, name, plan.Private.ValueBool()); err != nil {
 			resp.Diagnostics.AddError("Error updating private", err.Error())
 			return
 		}
 	}
 
 	if !plan.Hidden.Equal(state.Hidden) {
-		if err := r.client.RepoSetHidden(name, plan.Hidden.ValueBool()); err != nil {
+		if err := r.client.RepoSetHidden(ctx, name, plan.Hidden.ValueBool()); err != nil {
 			resp.Diagnostics.AddError("Error updating hidden", err.Error())
 			return
 		}
 	}
 
-	resp.Diagnostics.Append(r.readRepoState(name, &plan)...)
+	resp.Diagnostics.Append(r.applyCollaborators(ctx, name, state.Collaborators, plan.Collaborators)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.readRepoState(ctx, name, &plan)...)
+	resp.Diagnostics.Append(r.readCollaborators(ctx, name, &plan)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
@@ -213,16 +463,35 @@ func (r *RepositoryResource) Delete(ctx context.Context, req resource.DeleteRequ
 		return
 	}
 
-	if err := r.client.RepoDelete(state.Name.ValueString()); err != nil {
+	name := state.Name.ValueString()
+
+	if !state.ForceDestroy.ValueBool() {
+		info, err := r.client.RepoInfo(ctx, name)
+		if err != nil {
+			resp.Diagnostics.AddError("Error reading repository", err.Error())
+			return
+		}
+		if refs := nonDefaultRefs(info); len(refs) > 0 {
+			resp.Diagnostics.AddError("Repository is not empty",
+				fmt.Sprintf("Repository %q has refs besides its default branch: %s. Set force_destroy = true and apply before destroying it.",
+					name, strings.Join(refs, ", ")))
+			return
+		}
+	}
+
+	if err := r.client.RepoDelete(ctx, name); err != nil {
 		resp.Diagnostics.AddError("Error deleting repository", err.Error())
 	}
 }
 
 func (r *RepositoryResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
 	var model RepositoryResourceModel
-	model.Name = types.StringValue(req.ID)
+	model.ForceDestroy = types.BoolValue(false)
+	// Existing collaborators aren't imported as blocks: they may be managed
+	// by softserve_repository_collaborator resources.
+	model.Collaborators = types.SetValueMust(inlineCollaboratorType, nil)
 
-	resp.Diagnostics.Append(r.readRepoState(req.ID, &model)...)
+	resp.Diagnostics.Append(r.readRepoState(ctx, req.ID, &model)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
@@ -230,21 +499,251 @@ func (r *RepositoryResource) ImportState(ctx context.Context, req resource.Impor
 	resp.Diagnostics.Append(resp.State.Set(ctx, &model)...)
 }
 
-func (r *RepositoryResource) readRepoState(name string, model *RepositoryResourceModel) diag.Diagnostics {
+func (r *RepositoryResource) readRepoState(ctx context.Context, name string, model *RepositoryResourceModel) diag.Diagnostics {
 	var diags diag.Diagnostics
 
-	info, err := r.client.RepoInfo(name)
+	info, err := r.repoInfo(ctx, name)
 	if err != nil {
 		diags.AddError("Error reading repository", err.Error())
 		return diags
 	}
 
-	model.ID = types.StringValue(name)
-	model.Name = types.StringValue(info.Repository)
-	model.Description = types.StringValue(info.Description)
-	model.ProjectName = types.StringValue(info.ProjectName)
-	model.Private = types.BoolValue(info.Private)
+	// The server's name is canonical, so it is the id. name keeps what is
+	// configured, since replacing it would plan to recreate the repository,
+	// and only takes the server's name on import.
+	model.ID = types.StringValue(info.Repository)
+	if model.Name.IsNull() || model.Name.IsUnknown() {
+		model.Name = types.StringValue(info.Repository)
+	}
+	// An unset description stays null while the server has none
+	if info.Description != "" || !model.Description.IsNull() {
+		model.Description = types.StringValue(info.Description)
+	}
+	// Project names are stored trimmed, so configured surrounding whitespace
+	// is kept rather than showing as a change
+	if model.ProjectName.IsNull() || model.ProjectName.IsUnknown() || strings.TrimSpace(model.ProjectName.ValueString()) != info.ProjectName {
+		model.ProjectName = types.StringValue(info.ProjectName)
+	}
+	// Servers that omit Private leave the known value alone rather than
+	// having it read as public
+	if info.HasPrivate || model.Private.IsNull() || model.Private.IsUnknown() {
+		model.Private = types.BoolValue(info.Private)
+	}
 	model.Hidden = types.BoolValue(info.Hidden)
+	// A default branch the server doesn't report, e.g. before the first
+	// push, stays null rather than becoming "" and drifting
+	if info.DefaultBranch != "" {
+		model.DefaultBranch = types.StringValue(info.DefaultBranch)
+	} else if model.DefaultBranch.IsUnknown() {
+		model.DefaultBranch = types.StringNull()
+	}
+	model.UpstreamURL = optionalString(info.MirrorURL)
+	model.IsEmpty = types.BoolValue(len(info.Branches) == 0)
+	model.SSHCloneURL = types.StringValue(r.client.SSHCloneURL(info.Repository))
+	model.HTTPCloneURL = httpCloneURL(r.client, info.Repository)
+	model.CreatedAt = optionalString(info.CreatedAt)
+	model.UpdatedAt = optionalString(info.UpdatedAt)
+
+	return diags
+}
+
+// applyCollaborators adds or updates the collaborators in plan and removes
+// those only in state. Collaborators in neither are left alone.
+func (r *RepositoryResource) applyCollaborators(ctx context.Context, repo string, state, plan types.Set) diag.Diagnostics {
+	have, diags := inlineCollaborators(ctx, state)
+	want, d := inlineCollaborators(ctx, plan)
+	diags.Append(d...)
+	if diags.HasError() {
+		return diags
+	}
+
+	for _, username := range slices.Sorted(maps.Keys(want)) {
+		level := want[username]
+		got, ok := have[username]
+		if ok && sameAccessLevel(got, level) {
+			continue
+		}
+		add := r.client.CollabAdd
+		if ok {
+			add = r.client.CollabSetAccess
+		}
+		if err := add(ctx, repo, username, level); err != nil {
+			diags.AddError("Error adding collaborator",
+				fmt.Sprintf("Adding %q to %q: %s", username, repo, err))
+			return diags
+		}
+	}
+	for _, username := range slices.Sorted(maps.Keys(have)) {
+		if _, ok := want[username]; ok {
+			continue
+		}
+		if err := r.client.CollabRemove(ctx, repo, username); err != nil && !ssh.IsNotFound(err) {
+			diags.AddError("Error removing collaborator",
+				fmt.Sprintf("Removing %q from %q: %s", username, repo, err))
+			return diags
+		}
+	}
+	return diags
+}
+
+// readCollaborators refreshes the collaborator blocks in model from the
+// server. Blocks for users who are no longer collaborators are dropped, and
+// a block's access level is kept when the server's is equivalent. Nothing is
+// read when model has no blocks.
+func (r *RepositoryResource) readCollaborators(ctx context.Context, repo string, model *RepositoryResourceModel) diag.Diagnostics {
+	var blocks []InlineCollaboratorModel
+	diags := model.Collaborators.ElementsAs(ctx, &blocks, false)
+	if diags.HasError() {
+		return diags
+	}
+	if len(blocks) == 0 {
+		model.Collaborators = types.SetValueMust(inlineCollaboratorType, nil)
+		return diags
+	}
+
+	collabs, err := r.client.CollabList(ctx, repo)
+	if err != nil {
+		diags.AddError("Error listing collaborators", err.Error())
+		return diags
+	}
+	levels := make(map[string]string, len(collabs))
+	for _, c := range collabs {
+		levels[c.Username] = c.AccessLevel
+	}
+
+	var current []InlineCollaboratorModel
+	for _, b := range blocks {
+		level, ok := levels[b.Username.ValueString()]
+		if !ok {
+			continue
+		}
+		if !sameAccessLevel(b.AccessLevel.ValueString(), level) {
+			b.AccessLevel = types.StringValue(level)
+		}
+		current = append(current, b)
+	}
+	set, d := types.SetValueFrom(ctx, inlineCollaboratorType, current)
+	diags.Append(d...)
+	model.Collaborators = set
+	return diags
+}
 
+// inlineCollaborators maps the username of each collaborator block in set to
+// its access level, which is empty when unset.
+func inlineCollaborators(ctx context.Context, set types.Set) (map[string]string, diag.Diagnostics) {
+	var blocks []InlineCollaboratorModel
+	diags := set.ElementsAs(ctx, &blocks, false)
+	if diags.HasError() {
+		return nil, diags
+	}
+	levels := make(map[string]string, len(blocks))
+	for _, b := range blocks {
+		username := b.Username.ValueString()
+		if _, ok := levels[username]; ok {
+			diags.AddAttributeError(path.Root("collaborator"), "Duplicate collaborator",
+				fmt.Sprintf("User %q is listed in more than one collaborator block.", username))
+			continue
+		}
+		levels[username] = b.AccessLevel.ValueString()
+	}
+	return levels, diags
+}
+
+// repoInfo reads the repository's info, re-reading it a few times while the
+// server returns incomplete output, as it can right after `repo create`. The
+// wait is bounded by repoInfoAttempts and by ctx.
+func (r *RepositoryResource) repoInfo(ctx context.Context, name string) (*ssh.RepoInfoResult, error) {
+	for attempt := 1; ; attempt++ {
+		info, err := r.client.RepoInfo(ctx, name)
+		if !errors.Is(err, ssh.ErrIncompleteRepoInfo) || attempt == repoInfoAttempts {
+			return info, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w (waiting for complete repo info: %w)", err, ctx.Err())
+		case <-time.After(repoInfoRetryDelay):
+		}
+	}
+}
+
+// adoptRacedRepo handles `repo create` failing because name already exists,
+// as when concurrent applies create the same repository. A repository that is
+// still empty and matches the plan is taken to be the other apply's and is
+// adopted with a warning; anything else is an error, since it may hold data
+// that Terraform must not take over.
+func (r *RepositoryResource) adoptRacedRepo(ctx context.Context, name string, plan *RepositoryResourceModel, createErr error) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	info, err := r.repoInfo(ctx, name)
+	if err != nil {
+		diags.AddError("Error creating repository", fmt.Sprintf("%s\n\nReading the existing repository also failed: %s", createErr, err))
+		return diags
+	}
+	if mismatches := repoMismatches(info, plan); len(mismatches) > 0 {
+		diags.AddAttributeError(path.Root("name"), "Repository already exists",
+			fmt.Sprintf("Repository %q already exists and doesn't match the configuration: %s. Import it with `terraform import` or choose another name.",
+				name, strings.Join(mismatches, "; ")))
+		return diags
+	}
+	diags.AddWarning("Adopted existing repository",
+		fmt.Sprintf("Repository %q was created concurrently, probably by another apply. It is empty and matches the configuration, so it was adopted.", name))
 	return diags
 }
+
+// repoMismatches lists how the existing repository in info differs from what
+// plan would have created. Attributes the plan leaves to the server are not
+// compared.
+func repoMismatches(info *ssh.RepoInfoResult, plan *RepositoryResourceModel) []string {
+	var mismatches []string
+	if len(info.Branches) > 0 {
+		mismatches = append(mismatches, "it isn't empty")
+	}
+	if info.HasPrivate && info.Private != plan.Private.ValueBool() {
+		mismatches = append(mismatches, fmt.Sprintf("private is %t", info.Private))
+	}
+	if info.Hidden != plan.Hidden.ValueBool() {
+		mismatches = append(mismatches, fmt.Sprintf("hidden is %t", info.Hidden))
+	}
+	if !plan.Description.IsNull() && !plan.Description.IsUnknown() && info.Description != plan.Description.ValueString() {
+		mismatches = append(mismatches, fmt.Sprintf("description is %q", info.Description))
+	}
+	if !plan.ProjectName.IsNull() && !plan.ProjectName.IsUnknown() && info.ProjectName != strings.TrimSpace(plan.ProjectName.ValueString()) {
+		mismatches = append(mismatches, fmt.Sprintf("project name is %q", info.ProjectName))
+	}
+	if !plan.InitialBranch.IsNull() && !plan.InitialBranch.IsUnknown() && info.DefaultBranch != "" && info.DefaultBranch != plan.InitialBranch.ValueString() {
+		mismatches = append(mismatches, fmt.Sprintf("default branch is %q", info.DefaultBranch))
+	}
+	return mismatches
+}
+
+// nonDefaultRefs lists the branches other than the default branch, and all
+// tags, in info.
+func nonDefaultRefs(info *ssh.RepoInfoResult) []string {
+	var refs []string
+	for _, b := range info.Branches {
+		if b != info.DefaultBranch {
+			refs = append(refs, "branch "+b)
+		}
+	}
+	for _, t := range info.Tags {
+		refs = append(refs, "tag "+t)
+	}
+	return refs
+}
+
+// httpCloneURL returns the HTTP clone URL for repository name, or null when
+// the provider has no HTTP base URL configured.
+func httpCloneURL(client *ssh.Client, name string) types.String {
+	if u := client.HTTPCloneURL(name); u != "" {
+		return types.StringValue(u)
+	}
+	return types.StringNull()
+}
+
+// optionalString returns s as a string value, or null when it is empty.
+func optionalString(s string) types.String {
+	if s == "" {
+		return types.StringNull()
+	}
+	return types.StringValue(s)
+}
@@ -0,0 +1,200 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/ssoriche/terraform-provider-soft-serve/internal/ssh"
+)
+
+var (
+	_ resource.Resource                = &RepositoryBranchResource{}
+	_ resource.ResourceWithImportState = &RepositoryBranchResource{}
+)
+
+type RepositoryBranchResource struct {
+	client *ssh.Client
+}
+
+type RepositoryBranchResourceModel struct {
+	ID         types.String `tfsdk:"id"`
+	Repository types.String `tfsdk:"repository"`
+	Name       types.String `tfsdk:"name"`
+	From       types.String `tfsdk:"from"`
+}
+
+func NewRepositoryBranchResource() resource.Resource {
+	return &RepositoryBranchResource{}
+}
+
+func (r *RepositoryBranchResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_repository_branch"
+}
+
+func (r *RepositoryBranchResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Manages a branch in a Soft Serve repository.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "Branch identifier (repository:name).",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"repository": schema.StringAttribute{
+				Description: "Repository name.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"name": schema.StringAttribute{
+				Description: "Branch name.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"from": schema.StringAttribute{
+				Description: "Branch, tag or commit to create the branch from. Defaults to the repository's default branch.",
+				Optional:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+		},
+	}
+}
+
+func (r *RepositoryBranchResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(*ssh.Client)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *ssh.Client, got: %T", req.ProviderData))
+		return
+	}
+	r.client = client
+}
+
+func (r *RepositoryBranchResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan RepositoryBranchResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	repo := plan.Repository.ValueString()
+	name := plan.Name.ValueString()
+
+	if err := r.client.RepoBranchCreate(ctx, repo, name, plan.From.ValueString()); err != nil {
+		resp.Diagnostics.AddError("Error creating branch", err.Error())
+		return
+	}
+
+	exists, err := r.branchExists(ctx, repo, name)
+	if err != nil {
+		resp.Diagnostics.AddError("Error listing branches", err.Error())
+		return
+	}
+	if !exists {
+		resp.Diagnostics.AddError("Branch not found after create",
+			fmt.Sprintf("Branch %q was not listed on repository %q after creating it.", name, repo))
+		return
+	}
+
+	plan.ID = types.StringValue(branchID(repo, name))
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *RepositoryBranchResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state RepositoryBranchResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	exists, err := r.branchExists(ctx, state.Repository.ValueString(), state.Name.ValueString())
+	if err != nil && !ssh.IsNotFound(err) {
+		resp.Diagnostics.AddError("Error listing branches", err.Error())
+		return
+	}
+	if !exists {
+		// The branch, or the whole repository, was deleted outside Terraform
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+func (r *RepositoryBranchResource) Update(_ context.Context, _ resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// Every attribute requires replacement, so there is nothing to update
+	resp.Diagnostics.AddError("Update not supported", "All softserve_repository_branch attributes force a new resource.")
+}
+
+func (r *RepositoryBranchResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state RepositoryBranchResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.client.RepoBranchDelete(ctx, state.Repository.ValueString(), state.Name.ValueString()); err != nil {
+		resp.Diagnostics.AddError("Error deleting branch", err.Error())
+	}
+}
+
+func (r *RepositoryBranchResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	// Branch names commonly contain slashes, so the ID uses a colon, which
+	// git does not allow in ref names.
+	repo, name, ok := strings.Cut(req.ID, ":")
+	if !ok || repo == "" || name == "" {
+		resp.Diagnostics.AddError("Invalid import ID",
+			fmt.Sprintf("Expected format: repository:branch, got: %s", req.ID))
+		return
+	}
+
+	exists, err := r.branchExists(ctx, repo, name)
+	if err != nil {
+		resp.Diagnostics.AddError("Error listing branches", err.Error())
+		return
+	}
+	if !exists {
+		resp.Diagnostics.AddError("Branch not found",
+			fmt.Sprintf("Branch %q does not exist on repository %q", name, repo))
+		return
+	}
+
+	model := RepositoryBranchResourceModel{
+		ID:         types.StringValue(branchID(repo, name)),
+		Repository: types.StringValue(repo),
+		Name:       types.StringValue(name),
+		From:       types.StringNull(),
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &model)...)
+}
+
+// branchExists reports whether repo has a branch called name.
+func (r *RepositoryBranchResource) branchExists(ctx context.Context, repo, name string) (bool, error) {
+	branches, err := r.client.RepoBranchList(ctx, repo)
+	if err != nil {
+		return false, err
+	}
+	return slices.Contains(branches, name), nil
+}
+
+func branchID(repo, name string) string {
+	return repo + ":" + name
+}
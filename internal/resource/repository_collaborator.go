@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
@@ -71,9 +70,12 @@ func (r *RepositoryCollaboratorResource) Schema(_ context.Context, _ resource.Sc
 				Description: "Access level: no-access, read-only, read-write, or admin-access.",
 				Optional:    true,
 				Computed:    true,
-				Default:     stringdefault.StaticString("read-write"),
+				Default:     stringdefault.StaticString(string(ssh.AccessLevelReadWrite)),
+				PlanModifiers: []planmodifier.String{
+					EquivalentAccessLevelModifier(),
+				},
 				Validators: []validator.String{
-					stringvalidator.OneOf("no-access", "read-only", "read-write", "admin-access"),
+					AccessLevelValidator(),
 				},
 			},
 		},
@@ -104,12 +106,13 @@ func (r *RepositoryCollaboratorResource) Create(ctx context.Context, req resourc
 	username := plan.Username.ValueString()
 	accessLevel := plan.AccessLevel.ValueString()
 
-	if err := r.client.CollabAdd(repo, username, accessLevel); err != nil {
+	if err := r.client.CollabAdd(ctx, repo, username, accessLevel); err != nil {
 		resp.Diagnostics.AddError("Error adding collaborator", err.Error())
 		return
 	}
 
-	resp.Diagnostics.Append(r.readCollabState(repo, username, &plan)...)
+	_, diags := r.readCollabState(ctx, repo, username, &plan)
+	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
@@ -124,7 +127,13 @@ func (r *RepositoryCollaboratorResource) Read(ctx context.Context, req resource.
 		return
 	}
 
-	resp.Diagnostics.Append(r.readCollabState(state.Repository.ValueString(), state.Username.ValueString(), &state)...)
+	repoExists, diags := r.readCollabState(ctx, state.Repository.ValueString(), state.Username.ValueString(), &state)
+	if !repoExists {
+		// The repository was deleted, taking its collaborators with it
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
@@ -143,13 +152,21 @@ func (r *RepositoryCollaboratorResource) Update(ctx context.Context, req resourc
 	username := plan.Username.ValueString()
 	accessLevel := plan.AccessLevel.ValueString()
 
-	// collab add with a different access level updates the existing entry
-	if err := r.client.CollabAdd(repo, username, accessLevel); err != nil {
+	// Skip the add when the server already grants the planned level, in any
+	// spelling
+	current := plan
+	if _, diags := r.readCollabState(ctx, repo, username, &current); !diags.HasError() && sameAccessLevel(current.AccessLevel.ValueString(), accessLevel) {
+		resp.Diagnostics.Append(resp.State.Set(ctx, &current)...)
+		return
+	}
+
+	if err := r.client.CollabSetAccess(ctx, repo, username, accessLevel); err != nil {
 		resp.Diagnostics.AddError("Error updating collaborator", err.Error())
 		return
 	}
 
-	resp.Diagnostics.Append(r.readCollabState(repo, username, &plan)...)
+	_, diags := r.readCollabState(ctx, repo, username, &plan)
+	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
@@ -164,7 +181,7 @@ func (r *RepositoryCollaboratorResource) Delete(ctx context.Context, req resourc
 		return
 	}
 
-	if err := r.client.CollabRemove(state.Repository.ValueString(), state.Username.ValueString()); err != nil {
+	if err := r.client.CollabRemove(ctx, state.Repository.ValueString(), state.Username.ValueString()); err != nil {
 		resp.Diagnostics.AddError("Error removing collaborator", err.Error())
 	}
 }
@@ -181,7 +198,8 @@ func (r *RepositoryCollaboratorResource) ImportState(ctx context.Context, req re
 	model.Repository = types.StringValue(parts[0])
 	model.Username = types.StringValue(parts[1])
 
-	resp.Diagnostics.Append(r.readCollabState(parts[0], parts[1], &model)...)
+	_, diags := r.readCollabState(ctx, parts[0], parts[1], &model)
+	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
@@ -189,13 +207,18 @@ func (r *RepositoryCollaboratorResource) ImportState(ctx context.Context, req re
 	resp.Diagnostics.Append(resp.State.Set(ctx, &model)...)
 }
 
-func (r *RepositoryCollaboratorResource) readCollabState(repo, username string, model *RepositoryCollaboratorResourceModel) diag.Diagnostics {
-	var diags diag.Diagnostics
-
-	collabs, err := r.client.CollabList(repo)
+// readCollabState populates model from the server. repoExists is false when
+// the repository itself is missing, in which case diags explains why.
+func (r *RepositoryCollaboratorResource) readCollabState(ctx context.Context, repo, username string, model *RepositoryCollaboratorResourceModel) (repoExists bool, diags diag.Diagnostics) {
+	collabs, err := r.client.CollabList(ctx, repo)
 	if err != nil {
+		if ssh.IsNotFound(err) {
+			diags.AddError("Repository not found",
+				fmt.Sprintf("Repository %q does not exist, so %q cannot be a collaborator on it.", repo, username))
+			return false, diags
+		}
 		diags.AddError("Error listing collaborators", err.Error())
-		return diags
+		return true, diags
 	}
 
 	for _, c := range collabs {
@@ -205,14 +228,17 @@ func (r *RepositoryCollaboratorResource) readCollabState(repo, username string,
 			model.Username = types.StringValue(username)
 			accessLevel := c.AccessLevel
 			if accessLevel == "" {
-				accessLevel = "read-write"
+				accessLevel = string(ssh.AccessLevelReadWrite)
+			}
+			// Keep the model's spelling of an equivalent level
+			if model.AccessLevel.IsNull() || model.AccessLevel.IsUnknown() || !sameAccessLevel(model.AccessLevel.ValueString(), accessLevel) {
+				model.AccessLevel = types.StringValue(accessLevel)
 			}
-			model.AccessLevel = types.StringValue(accessLevel)
-			return diags
+			return true, diags
 		}
 	}
 
 	diags.AddError("Collaborator not found",
 		fmt.Sprintf("User %q is not a collaborator on repository %q", username, repo))
-	return diags
+	return true, diags
 }
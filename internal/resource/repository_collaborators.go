@@ -0,0 +1,435 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	pathpkg "path"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/mapvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/ssoriche/terraform-provider-soft-serve/internal/ssh"
+)
+
+var (
+	_ resource.Resource                   = &RepositoryCollaboratorsResource{}
+	_ resource.ResourceWithImportState    = &RepositoryCollaboratorsResource{}
+	_ resource.ResourceWithModifyPlan     = &RepositoryCollaboratorsResource{}
+	_ resource.ResourceWithValidateConfig = &RepositoryCollaboratorsResource{}
+)
+
+// RepositoryCollaboratorsResource manages the complete collaborator list of a
+// repository: collaborators missing from the configuration are removed,
+// unless they match one of its ignore patterns.
+type RepositoryCollaboratorsResource struct {
+	client *ssh.Client
+}
+
+type RepositoryCollaboratorsResourceModel struct {
+	ID            types.String `tfsdk:"id"`
+	Repository    types.String `tfsdk:"repository"`
+	Collaborators types.Map    `tfsdk:"collaborators"`
+	Ignore        types.Set    `tfsdk:"ignore"`
+}
+
+func NewRepositoryCollaboratorsResource() resource.Resource {
+	return &RepositoryCollaboratorsResource{}
+}
+
+func (r *RepositoryCollaboratorsResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_repository_collaborators"
+}
+
+func (r *RepositoryCollaboratorsResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Authoritatively manages the collaborators on a Soft Serve repository. Collaborators not listed are removed, so don't combine this with softserve_repository_collaborator on the same repository.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "Identifier (same as repository).",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"repository": schema.StringAttribute{
+				Description: "Repository name.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"collaborators": schema.MapAttribute{
+				Description: "Access level of each collaborator, keyed by username: no-access, read-only, read-write, or admin-access.",
+				Required:    true,
+				ElementType: types.StringType,
+				Validators: []validator.Map{
+					mapvalidator.ValueStringsAre(AccessLevelValidator()),
+				},
+			},
+			"ignore": schema.SetAttribute{
+				Description: "Glob patterns, e.g. \"bot-*\", of usernames to leave alone: matching collaborators that aren't listed are neither removed nor tracked, so accounts managed elsewhere can keep their access. Patterns match usernames case-insensitively and support *, ? and [...].",
+				Optional:    true,
+				ElementType: types.StringType,
+				Validators: []validator.Set{
+					setvalidator.ValueStringsAre(GlobPatternValidator()),
+				},
+			},
+		},
+	}
+}
+
+func (r *RepositoryCollaboratorsResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(*ssh.Client)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *ssh.Client, got: %T", req.ProviderData))
+		return
+	}
+	r.client = client
+}
+
+func (r *RepositoryCollaboratorsResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan RepositoryCollaboratorsResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.apply(ctx, &plan, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *RepositoryCollaboratorsResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state RepositoryCollaboratorsResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	repo := state.Repository.ValueString()
+	current, err := r.client.CollabList(ctx, repo)
+	if err != nil {
+		if ssh.IsNotFound(err) {
+			// The repository was deleted, taking its collaborators with it
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError("Error listing collaborators", err.Error())
+		return
+	}
+
+	ignore, diags := ignorePatterns(ctx, state.Ignore)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(setCollaboratorsState(ctx, repo, current, ignore, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+func (r *RepositoryCollaboratorsResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan RepositoryCollaboratorsResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.apply(ctx, &plan, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *RepositoryCollaboratorsResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state RepositoryCollaboratorsResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var collaborators map[string]string
+	resp.Diagnostics.Append(state.Collaborators.ElementsAs(ctx, &collaborators, false)...)
+	ignore, diags := ignorePatterns(ctx, state.Ignore)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	repo := state.Repository.ValueString()
+	for _, username := range slices.Sorted(maps.Keys(withoutIgnored(collaborators, ignore))) {
+		if err := r.client.CollabRemove(ctx, repo, username); err != nil && !ssh.IsNotFound(err) {
+			resp.Diagnostics.AddError("Error removing collaborator",
+				fmt.Sprintf("Removing %q from %q: %s", username, repo, err))
+			return
+		}
+	}
+}
+
+// ModifyPlan warns about the collaborators that applying the plan will add,
+// change or remove on the server. Collaborators that aren't in the state,
+// such as ones added outside Terraform before this resource was created,
+// would otherwise be removed without the plan showing it.
+func (r *RepositoryCollaboratorsResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || r.client == nil {
+		return
+	}
+
+	var plan RepositoryCollaboratorsResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if plan.Repository.IsUnknown() || plan.Collaborators.IsUnknown() || plan.Ignore.IsUnknown() {
+		return
+	}
+	var want map[string]string
+	resp.Diagnostics.Append(plan.Collaborators.ElementsAs(ctx, &want, false)...)
+	ignore, diags := ignorePatterns(ctx, plan.Ignore)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	repo := plan.Repository.ValueString()
+	current, err := r.client.CollabList(ctx, repo)
+	if err != nil && !ssh.IsNotFound(err) {
+		// The preview is informational; apply reports any real problem
+		resp.Diagnostics.AddWarning("Unable to preview collaborator changes",
+			fmt.Sprintf("Listing the collaborators of %q: %s", repo, err))
+		return
+	}
+
+	if changes := collaboratorChanges(withoutIgnored(collaboratorLevels(current), ignore), want); len(changes) > 0 {
+		resp.Diagnostics.AddWarning(fmt.Sprintf("Collaborator changes on %q", repo),
+			"Applying will make these changes on the server:\n\n"+strings.Join(changes, "\n"))
+	}
+}
+
+// ValidateConfig rejects listing a collaborator that an ignore pattern also
+// matches, since the resource would both manage and leave it alone.
+func (r *RepositoryCollaboratorsResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config RepositoryCollaboratorsResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() || config.Collaborators.IsUnknown() || config.Ignore.IsUnknown() {
+		return
+	}
+	var want map[string]string
+	resp.Diagnostics.Append(config.Collaborators.ElementsAs(ctx, &want, false)...)
+	ignore, diags := ignorePatterns(ctx, config.Ignore)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	for _, username := range slices.Sorted(maps.Keys(want)) {
+		if pattern, ok := ignoredBy(username, ignore); ok {
+			resp.Diagnostics.AddAttributeError(path.Root("collaborators").AtMapKey(username), "Collaborator is ignored",
+				fmt.Sprintf("%q matches the ignore pattern %q. Remove it from collaborators or narrow the pattern.", username, pattern))
+		}
+	}
+}
+
+// ImportState imports every current collaborator of the repository named by
+// the import ID.
+func (r *RepositoryCollaboratorsResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	current, err := r.client.CollabList(ctx, req.ID)
+	if err != nil {
+		if ssh.IsNotFound(err) {
+			resp.Diagnostics.AddAttributeError(path.Root("repository"), "Repository not found",
+				fmt.Sprintf("Repository %q does not exist.", req.ID))
+			return
+		}
+		resp.Diagnostics.AddError("Error listing collaborators", err.Error())
+		return
+	}
+
+	model := RepositoryCollaboratorsResourceModel{Ignore: types.SetNull(types.StringType)}
+	resp.Diagnostics.Append(setCollaboratorsState(ctx, req.ID, current, nil, &model)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &model)...)
+}
+
+// apply makes the server's collaborators match plan, adding or changing the
+// planned ones and removing any others that aren't ignored, then reads the
+// result back into plan.
+func (r *RepositoryCollaboratorsResource) apply(ctx context.Context, plan *RepositoryCollaboratorsResourceModel, diags *diag.Diagnostics) {
+	var want map[string]string
+	diags.Append(plan.Collaborators.ElementsAs(ctx, &want, false)...)
+	ignore, d := ignorePatterns(ctx, plan.Ignore)
+	diags.Append(d...)
+	if diags.HasError() {
+		return
+	}
+
+	repo := plan.Repository.ValueString()
+	current, err := r.client.CollabList(ctx, repo)
+	if err != nil {
+		diags.AddError("Error listing collaborators", err.Error())
+		return
+	}
+	have := withoutIgnored(collaboratorLevels(current), ignore)
+
+	for _, username := range slices.Sorted(maps.Keys(want)) {
+		level := want[username]
+		got, ok := have[username]
+		switch {
+		case !ok:
+			err = r.client.CollabAdd(ctx, repo, username, level)
+		case !sameAccessLevel(got, level):
+			err = r.client.CollabSetAccess(ctx, repo, username, level)
+		default:
+			continue
+		}
+		if err != nil {
+			diags.AddError("Error adding collaborator",
+				fmt.Sprintf("Adding %q to %q: %s", username, repo, err))
+			return
+		}
+	}
+	for _, username := range slices.Sorted(maps.Keys(have)) {
+		if _, ok := want[username]; ok {
+			continue
+		}
+		if err := r.client.CollabRemove(ctx, repo, username); err != nil {
+			diags.AddError("Error removing collaborator",
+				fmt.Sprintf("Removing %q from %q: %s", username, repo, err))
+			return
+		}
+	}
+
+	current, err = r.client.CollabList(ctx, repo)
+	if err != nil {
+		diags.AddError("Error listing collaborators", err.Error())
+		return
+	}
+	diags.Append(setCollaboratorsState(ctx, repo, current, ignore, plan)...)
+}
+
+// collaboratorChanges describes, one line per collaborator in username order,
+// what making have match want adds (+), changes (~) and removes (-).
+func collaboratorChanges(have, want map[string]string) []string {
+	var changes []string
+	for _, username := range slices.Sorted(maps.Keys(want)) {
+		got, ok := have[username]
+		switch {
+		case !ok:
+			changes = append(changes, fmt.Sprintf("  + %s (%s)", username, want[username]))
+		case !sameAccessLevel(got, want[username]):
+			changes = append(changes, fmt.Sprintf("  ~ %s (%s -> %s)", username, got, want[username]))
+		}
+	}
+	for _, username := range slices.Sorted(maps.Keys(have)) {
+		if _, ok := want[username]; !ok {
+			changes = append(changes, fmt.Sprintf("  - %s (%s)", username, have[username]))
+		}
+	}
+	return changes
+}
+
+// setCollaboratorsState fills model with repo's collaborators, leaving out
+// the ones ignore matches. A collaborator keeps the access level model
+// already has for it when the server's is equivalent, so an alias or
+// different casing on the server doesn't show as a change.
+func setCollaboratorsState(ctx context.Context, repo string, collabs []ssh.CollabEntry, ignore []string, model *RepositoryCollaboratorsResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+	var prior map[string]string
+	if !model.Collaborators.IsNull() && !model.Collaborators.IsUnknown() {
+		diags.Append(model.Collaborators.ElementsAs(ctx, &prior, false)...)
+		if diags.HasError() {
+			return diags
+		}
+	}
+
+	levels := withoutIgnored(collaboratorLevels(collabs), ignore)
+	for username, level := range levels {
+		if p, ok := prior[username]; ok && sameAccessLevel(p, level) {
+			levels[username] = p
+		}
+	}
+
+	model.ID = types.StringValue(repo)
+	model.Repository = types.StringValue(repo)
+	collaborators, d := types.MapValueFrom(ctx, types.StringType, levels)
+	diags.Append(d...)
+	model.Collaborators = collaborators
+	return diags
+}
+
+// collaboratorLevels maps each collaborator's username to its access level.
+// Entries without a level get read-write, the server's default.
+func collaboratorLevels(collabs []ssh.CollabEntry) map[string]string {
+	levels := make(map[string]string, len(collabs))
+	for _, c := range collabs {
+		level := c.AccessLevel
+		if level == "" {
+			level = string(ssh.AccessLevelReadWrite)
+		}
+		levels[c.Username] = level
+	}
+	return levels
+}
+
+// ignorePatterns returns the patterns in an ignore set, or none when it is
+// null or unknown.
+func ignorePatterns(ctx context.Context, set types.Set) ([]string, diag.Diagnostics) {
+	if set.IsNull() || set.IsUnknown() {
+		return nil, nil
+	}
+	var patterns []string
+	diags := set.ElementsAs(ctx, &patterns, false)
+	return patterns, diags
+}
+
+// ignoredBy returns the first of patterns that matches username, ignoring
+// case like the server does. Invalid patterns, which validation rejects,
+// match nothing.
+func ignoredBy(username string, patterns []string) (string, bool) {
+	for _, pattern := range patterns {
+		if ok, _ := pathpkg.Match(strings.ToLower(pattern), strings.ToLower(username)); ok {
+			return pattern, true
+		}
+	}
+	return "", false
+}
+
+// withoutIgnored returns levels without the collaborators patterns match.
+func withoutIgnored(levels map[string]string, patterns []string) map[string]string {
+	if len(patterns) == 0 {
+		return levels
+	}
+	kept := make(map[string]string, len(levels))
+	for username, level := range levels {
+		if _, ok := ignoredBy(username, patterns); !ok {
+			kept[username] = level
+		}
+	}
+	return kept
+}
@@ -0,0 +1,260 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/ssoriche/terraform-provider-soft-serve/internal/ssh"
+)
+
+var (
+	_ resource.Resource                = &RepositoryFileResource{}
+	_ resource.ResourceWithImportState = &RepositoryFileResource{}
+)
+
+// Defaults for the commits a softserve_repository_file makes.
+const (
+	DefaultFileCommitMessage = "Managed by Terraform"
+	DefaultFileAuthorName    = "Terraform"
+	DefaultFileAuthorEmail   = "terraform@localhost"
+)
+
+type RepositoryFileResource struct {
+	client *ssh.Client
+}
+
+type RepositoryFileResourceModel struct {
+	ID            types.String `tfsdk:"id"`
+	Repository    types.String `tfsdk:"repository"`
+	Branch        types.String `tfsdk:"branch"`
+	Path          types.String `tfsdk:"path"`
+	Content       types.String `tfsdk:"content"`
+	CommitMessage types.String `tfsdk:"commit_message"`
+	AuthorName    types.String `tfsdk:"author_name"`
+	AuthorEmail   types.String `tfsdk:"author_email"`
+	CommitSHA     types.String `tfsdk:"commit_sha"`
+}
+
+func NewRepositoryFileResource() resource.Resource {
+	return &RepositoryFileResource{}
+}
+
+func (r *RepositoryFileResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_repository_file"
+}
+
+func (r *RepositoryFileResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Manages the content of a file on a branch of a Soft Serve repository by committing to it. " +
+			"Advanced: Soft Serve has no command for writing files, so each change fetches the branch and pushes a commit over git's own protocol. " +
+			"Creating the resource overwrites an existing file, and destroying it commits the file's removal.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "File identifier (repository:branch:path).",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"repository": schema.StringAttribute{
+				Description: "Repository name.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"branch": schema.StringAttribute{
+				Description: "Branch to commit to. It must already exist, unless the repository has no commits yet.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"path": schema.StringAttribute{
+				Description: "Path of the file within the repository, e.g. \"docs/README.md\". Missing directories are created.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"content": schema.StringAttribute{
+				Description: "Content of the file.",
+				Required:    true,
+			},
+			"commit_message": schema.StringAttribute{
+				Description: fmt.Sprintf("Message of the commits that create or change the file. Defaults to %q.", DefaultFileCommitMessage),
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString(DefaultFileCommitMessage),
+			},
+			"author_name": schema.StringAttribute{
+				Description: fmt.Sprintf("Author and committer name of the commits. Defaults to %q.", DefaultFileAuthorName),
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString(DefaultFileAuthorName),
+			},
+			"author_email": schema.StringAttribute{
+				Description: fmt.Sprintf("Author and committer email of the commits. Defaults to %q.", DefaultFileAuthorEmail),
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString(DefaultFileAuthorEmail),
+			},
+			"commit_sha": schema.StringAttribute{
+				Description: "Hash of the commit that last wrote the file, or of the branch head when the file already had its content.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func (r *RepositoryFileResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(*ssh.Client)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *ssh.Client, got: %T", req.ProviderData))
+		return
+	}
+	r.client = client
+}
+
+func (r *RepositoryFileResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan RepositoryFileResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	commit, err := r.client.RepoPutFile(ctx, plan.Repository.ValueString(), plan.Branch.ValueString(),
+		plan.Path.ValueString(), plan.Content.ValueString(), fileCommitOpts(&plan, plan.CommitMessage.ValueString()))
+	if err != nil {
+		resp.Diagnostics.AddError("Error writing file", err.Error())
+		return
+	}
+
+	plan.ID = types.StringValue(fileID(plan.Repository.ValueString(), plan.Branch.ValueString(), plan.Path.ValueString()))
+	plan.CommitSHA = types.StringValue(commit)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *RepositoryFileResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state RepositoryFileResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	content, err := r.client.RepoBlob(ctx, state.Repository.ValueString(), state.Branch.ValueString(), state.Path.ValueString())
+	if err != nil {
+		if ssh.IsNotFound(err) {
+			// The file, branch or repository was deleted outside Terraform
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError("Error reading file", err.Error())
+		return
+	}
+	state.Content = types.StringValue(content)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+func (r *RepositoryFileResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan, state RepositoryFileResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Changing only the commit message or author affects future commits;
+	// there is nothing to commit now.
+	plan.CommitSHA = state.CommitSHA
+	if !plan.Content.Equal(state.Content) {
+		commit, err := r.client.RepoPutFile(ctx, plan.Repository.ValueString(), plan.Branch.ValueString(),
+			plan.Path.ValueString(), plan.Content.ValueString(), fileCommitOpts(&plan, plan.CommitMessage.ValueString()))
+		if err != nil {
+			resp.Diagnostics.AddError("Error writing file", err.Error())
+			return
+		}
+		plan.CommitSHA = types.StringValue(commit)
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *RepositoryFileResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state RepositoryFileResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	path := state.Path.ValueString()
+	_, err := r.client.RepoDeleteFile(ctx, state.Repository.ValueString(), state.Branch.ValueString(),
+		path, fileCommitOpts(&state, "Delete "+path))
+	if err != nil && !ssh.IsNotFound(err) && !errors.Is(err, ssh.ErrBranchNotFound) {
+		resp.Diagnostics.AddError("Error deleting file", err.Error())
+	}
+}
+
+func (r *RepositoryFileResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	// Neither repository nor branch names can contain a colon, so the path,
+	// which can, comes last.
+	parts := strings.SplitN(req.ID, ":", 3)
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		resp.Diagnostics.AddError("Invalid import ID",
+			fmt.Sprintf("Expected format: repository:branch:path, got: %s", req.ID))
+		return
+	}
+	repo, branch, path := parts[0], parts[1], parts[2]
+
+	content, err := r.client.RepoBlob(ctx, repo, branch, path)
+	if err != nil {
+		if ssh.IsNotFound(err) {
+			resp.Diagnostics.AddError("File not found",
+				fmt.Sprintf("File %q does not exist on branch %q of repository %q", path, branch, repo))
+			return
+		}
+		resp.Diagnostics.AddError("Error reading file", err.Error())
+		return
+	}
+
+	model := RepositoryFileResourceModel{
+		ID:            types.StringValue(fileID(repo, branch, path)),
+		Repository:    types.StringValue(repo),
+		Branch:        types.StringValue(branch),
+		Path:          types.StringValue(path),
+		Content:       types.StringValue(content),
+		CommitMessage: types.StringValue(DefaultFileCommitMessage),
+		AuthorName:    types.StringValue(DefaultFileAuthorName),
+		AuthorEmail:   types.StringValue(DefaultFileAuthorEmail),
+		CommitSHA:     types.StringNull(),
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &model)...)
+}
+
+// fileCommitOpts returns the options for a commit to model's file with
+// message.
+func fileCommitOpts(model *RepositoryFileResourceModel, message string) ssh.CommitOpts {
+	return ssh.CommitOpts{
+		Message:     message,
+		AuthorName:  model.AuthorName.ValueString(),
+		AuthorEmail: model.AuthorEmail.ValueString(),
+	}
+}
+
+func fileID(repo, branch, path string) string {
+	return repo + ":" + branch + ":" + path
+}
@@ -3,14 +3,17 @@ package resource
 import (
 	"context"
 	"fmt"
+	"strings"
 
-	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 
 	"github.com/ssoriche/terraform-provider-soft-serve/internal/ssh"
@@ -21,6 +24,15 @@ var (
 	_ resource.ResourceWithImportState = &ServerSettingsResource{}
 )
 
+// DefaultAnonAccess is Soft Serve's anon-access setting on a new server. It
+// is applied when anon_access is removed from the configuration.
+const DefaultAnonAccess = ssh.AccessLevelReadOnly
+
+// configuredAnonAccessKey is the private state key marking anon_access as set
+// in the configuration last applied, so that removing it can be told apart
+// from never having set it.
+const configuredAnonAccessKey = "configured_anon_access"
+
 type ServerSettingsResource struct {
 	client *ssh.Client
 }
@@ -54,13 +66,19 @@ func (r *ServerSettingsResource) Schema(_ context.Context, _ resource.SchemaRequ
 				Description: "Whether to allow keyless access to repositories.",
 				Optional:    true,
 				Computed:    true,
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.UseStateForUnknown(),
+				},
 			},
 			"anon_access": schema.StringAttribute{
-				Description: "Default access level for anonymous users: no-access, read-only, read-write, or admin-access.",
+				Description: fmt.Sprintf("Default access level for anonymous users: no-access, read-only, read-write, or admin-access. When never set, the server's current value is kept. Removing it after it was set resets the server to %s, the Soft Serve default.", DefaultAnonAccess),
 				Optional:    true,
 				Computed:    true,
 				Validators: []validator.String{
-					stringvalidator.OneOf("no-access", "read-only", "read-write", "admin-access"),
+					AccessLevelValidator(),
+				},
+				PlanModifiers: []planmodifier.String{
+					anonAccessResetModifier{},
 				},
 			},
 		},
@@ -87,17 +105,23 @@ func (r *ServerSettingsResource) Create(ctx context.Context, req resource.Create
 		return
 	}
 
-	resp.Diagnostics.Append(r.applySettings(&plan)...)
+	resp.Diagnostics.Append(r.checkAdmin(ctx)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	resp.Diagnostics.Append(r.readSettingsState(&plan)...)
+	resp.Diagnostics.Append(r.applySettings(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.readSettingsState(ctx, &plan)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+	resp.Diagnostics.Append(rememberAnonAccessConfigured(ctx, req.Config, resp.Private)...)
 }
 
 func (r *ServerSettingsResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
@@ -107,7 +131,7 @@ func (r *ServerSettingsResource) Read(ctx context.Context, req resource.ReadRequ
 		return
 	}
 
-	resp.Diagnostics.Append(r.readSettingsState(&state)...)
+	resp.Diagnostics.Append(r.readSettingsState(ctx, &state)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
@@ -122,17 +146,23 @@ func (r *ServerSettingsResource) Update(ctx context.Context, req resource.Update
 		return
 	}
 
-	resp.Diagnostics.Append(r.applySettings(&plan)...)
+	resp.Diagnostics.Append(r.checkAdmin(ctx)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.applySettings(ctx, &plan)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	resp.Diagnostics.Append(r.readSettingsState(&plan)...)
+	resp.Diagnostics.Append(r.readSettingsState(ctx, &plan)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+	resp.Diagnostics.Append(rememberAnonAccessConfigured(ctx, req.Config, resp.Private)...)
 }
 
 func (r *ServerSettingsResource) Delete(_ context.Context, _ resource.DeleteRequest, _ *resource.DeleteResponse) {
@@ -142,7 +172,7 @@ func (r *ServerSettingsResource) Delete(_ context.Context, _ resource.DeleteRequ
 func (r *ServerSettingsResource) ImportState(ctx context.Context, _ resource.ImportStateRequest, resp *resource.ImportStateResponse) {
 	var model ServerSettingsResourceModel
 
-	resp.Diagnostics.Append(r.readSettingsState(&model)...)
+	resp.Diagnostics.Append(r.readSettingsState(ctx, &model)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
@@ -150,18 +180,83 @@ func (r *ServerSettingsResource) ImportState(ctx context.Context, _ resource.Imp
 	resp.Diagnostics.Append(resp.State.Set(ctx, &model)...)
 }
 
-func (r *ServerSettingsResource) applySettings(model *ServerSettingsResourceModel) diag.Diagnostics {
+// anonAccessResetModifier plans anon_access when the configuration doesn't
+// set it. If the last applied configuration set it, removing it plans
+// DefaultAnonAccess; otherwise, as after creating the resource without it or
+// importing it, the current value is kept so the server isn't changed.
+type anonAccessResetModifier struct{}
+
+func (m anonAccessResetModifier) Description(_ context.Context) string {
+	return "Plans the Soft Serve default once anon_access is removed from the configuration, and otherwise keeps the current value."
+}
+
+func (m anonAccessResetModifier) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+func (m anonAccessResetModifier) PlanModifyString(ctx context.Context, req planmodifier.StringRequest, resp *planmodifier.StringResponse) {
+	if !req.ConfigValue.IsNull() || req.StateValue.IsNull() {
+		return
+	}
+	configured, diags := req.Private.GetKey(ctx, configuredAnonAccessKey)
+	resp.Diagnostics.Append(diags...)
+	if configured != nil {
+		resp.PlanValue = types.StringValue(string(DefaultAnonAccess))
+		return
+	}
+	resp.PlanValue = req.StateValue
+}
+
+// privateState is the part of a response's private state that
+// rememberAnonAccessConfigured writes to.
+type privateState interface {
+	SetKey(ctx context.Context, key string, value []byte) diag.Diagnostics
+}
+
+// rememberAnonAccessConfigured records in private whether config sets
+// anon_access, for anonAccessResetModifier to check on the next plan.
+func rememberAnonAccessConfigured(ctx context.Context, config tfsdk.Config, private privateState) diag.Diagnostics {
+	var configured types.String
+	diags := config.GetAttribute(ctx, path.Root("anon_access"), &configured)
+	if diags.HasError() {
+		return diags
+	}
+	var marker []byte
+	if !configured.IsNull() {
+		marker = []byte("true")
+	}
+	diags.Append(private.SetKey(ctx, configuredAnonAccessKey, marker)...)
+	return diags
+}
+
+// checkAdmin fails when the configured user isn't an admin, so that a
+// non-admin gets a clear error before any setting is changed rather than a
+// command failure partway through. When `info` can't be run or read, the
+// check is skipped and applying the settings reports any real problem.
+func (r *ServerSettingsResource) checkAdmin(ctx context.Context) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	info, err := r.client.Info(ctx)
+	if err != nil || info.Admin || info.Access == string(ssh.AccessLevelAdminAccess) {
+		return diags
+	}
+	diags.AddError("Admin access required",
+		fmt.Sprintf("Managing server settings requires an admin user, but the provider is authenticated as %q, which is not an admin. Configure the provider with an admin user's key.", info.Username))
+	return diags
+}
+
+func (r *ServerSettingsResource) applySettings(ctx context.Context, model *ServerSettingsResourceModel) diag.Diagnostics {
 	var diags diag.Diagnostics
 
 	if !model.AllowKeyless.IsNull() && !model.AllowKeyless.IsUnknown() {
-		if err := r.client.SettingsSetAllowKeyless(model.AllowKeyless.ValueBool()); err != nil {
+		if err := r.client.SettingsSetAllowKeyless(ctx, model.AllowKeyless.ValueBool()); err != nil {
 			diags.AddError("Error setting allow-keyless", err.Error())
 			return diags
 		}
 	}
 
 	if !model.AnonAccess.IsNull() && !model.AnonAccess.IsUnknown() {
-		if err := r.client.SettingsSetAnonAccess(model.AnonAccess.ValueString()); err != nil {
+		if err := r.client.SettingsSetAnonAccess(ctx, model.AnonAccess.ValueString()); err != nil {
 			diags.AddError("Error setting anon-access", err.Error())
 			return diags
 		}
@@ -170,24 +265,40 @@ func (r *ServerSettingsResource) applySettings(model *ServerSettingsResourceMode
 	return diags
 }
 
-func (r *ServerSettingsResource) readSettingsState(model *ServerSettingsResourceModel) diag.Diagnostics {
+func (r *ServerSettingsResource) readSettingsState(ctx context.Context, model *ServerSettingsResourceModel) diag.Diagnostics {
 	var diags diag.Diagnostics
 
 	model.ID = types.StringValue("settings")
 
-	allowKeyless, err := r.client.SettingsGetAllowKeyless()
+	allowKeyless, err := r.client.SettingsGetAllowKeyless(ctx)
 	if err != nil {
 		diags.AddError("Error reading allow-keyless", err.Error())
 		return diags
 	}
 	model.AllowKeyless = types.BoolValue(allowKeyless)
 
-	anonAccess, err := r.client.SettingsGetAnonAccess()
+	anonAccess, err := r.client.SettingsGetAnonAccess(ctx)
 	if err != nil {
 		diags.AddError("Error reading anon-access", err.Error())
 		return diags
 	}
-	model.AnonAccess = types.StringValue(anonAccess)
+	// Writes are validated strictly, but older servers may report levels in
+	// another spelling; map those to the canonical value rather than storing
+	// something that would never match the configuration.
+	level, ok := ssh.NormalizeAccessLevel(anonAccess)
+	switch {
+	case !ok:
+		diags.AddWarning("Unrecognized anon-access value",
+			fmt.Sprintf("The server reported anon-access %q, which is not one of %s. The value is kept as reported; set anon_access to replace it.",
+				anonAccess, strings.Join(ssh.AccessLevelStrings(), ", ")))
+		model.AnonAccess = types.StringValue(anonAccess)
+	case string(level) != anonAccess:
+		diags.AddWarning("Normalized anon-access value",
+			fmt.Sprintf("The server reported anon-access %q, which was read as %q.", anonAccess, level))
+		model.AnonAccess = types.StringValue(string(level))
+	default:
+		model.AnonAccess = types.StringValue(anonAccess)
+	}
 
 	return diags
 }
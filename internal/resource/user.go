@@ -2,10 +2,15 @@ package resource
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"sort"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
 
 	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
@@ -26,12 +31,20 @@ type UserResource struct {
 }
 
 type UserResourceModel struct {
-	ID         types.String `tfsdk:"id"`
-	Username   types.String `tfsdk:"username"`
-	Admin      types.Bool   `tfsdk:"admin"`
-	PublicKeys types.Set    `tfsdk:"public_keys"`
+	ID           types.String `tfsdk:"id"`
+	Username     types.String `tfsdk:"username"`
+	Admin        types.Bool   `tfsdk:"admin"`
+	PublicKeys   types.Set    `tfsdk:"public_keys"`
+	Fingerprints types.List   `tfsdk:"fingerprints"`
+
+	IgnoreKeyChanges types.Bool `tfsdk:"ignore_key_changes"`
+	ForceDestroy     types.Bool `tfsdk:"force_destroy"`
 }
 
+// ownerScanWorkers bounds how many repositories are read at once when
+// checking which ones a user owns before deleting it.
+const ownerScanWorkers = 8
+
 func NewUserResource() resource.Resource {
 	return &UserResource{}
 }
@@ -65,10 +78,27 @@ func (r *UserResource) Schema(_ context.Context, _ resource.SchemaRequest, resp
 				Default:     booldefault.StaticBool(false),
 			},
 			"public_keys": schema.SetAttribute{
-				Description: "Set of SSH public keys for the user.",
+				Description: "Set of SSH public keys for the user. A comment after a key, e.g. \"alice@laptop\", is kept as its title; Soft Serve doesn't store it, so changing only a key's comment changes nothing on the server.",
 				Optional:    true,
 				ElementType: types.StringType,
 			},
+			"fingerprints": schema.ListAttribute{
+				Description: "SHA256 fingerprints of the user's public keys, in sorted key order. A key that can't be parsed has an empty fingerprint.",
+				Computed:    true,
+				ElementType: types.StringType,
+			},
+			"ignore_key_changes": schema.BoolAttribute{
+				Description: "Only set public_keys when the user is created. Afterwards, changes to public_keys are stored without being applied, and keys added or removed on the server, e.g. by another tool rotating them, don't show as drift; fingerprints still reflects the server's keys. Defaults to false.",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+			},
+			"force_destroy": schema.BoolAttribute{
+				Description: "Whether to delete the user even if it owns repositories, which would leave them without an owner. Checking reads every repository the configured user can see. Must be applied before the destroy to take effect. Defaults to false.",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+			},
 		},
 	}
 }
@@ -108,7 +138,13 @@ func (r *UserResource) Create(ctx context.Context, req resource.CreateRequest, r
 		PublicKeys: keys,
 	}
 
-	if err := r.client.UserCreate(username, opts); err != nil {
+	if err := r.client.UserCreate(ctx, username, opts); err != nil {
+		if len(keys) > 0 && ssh.IsKeyConflict(err) {
+			if diags := r.keyConflict(ctx, keys); diags.HasError() {
+				resp.Diagnostics.Append(diags...)
+				return
+			}
+		}
 		resp.Diagnostics.AddError("Error creating user", err.Error())
 		return
 	}
@@ -119,6 +155,7 @@ func (r *UserResource) Create(ctx context.Context, req resource.CreateRequest, r
 	}
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+	resp.Diagnostics.Append(unparsedKeysWarning(ctx, username, plan.Fingerprints)...)
 }
 
 func (r *UserResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
@@ -148,14 +185,21 @@ func (r *UserResource) Update(ctx context.Context, req resource.UpdateRequest, r
 
 	// Update admin status
 	if !plan.Admin.Equal(state.Admin) {
-		if err := r.client.UserSetAdmin(username, plan.Admin.ValueBool()); err != nil {
+		if state.Admin.ValueBool() && r.client.ProtectsLastAdmin() {
+			resp.Diagnostics.Append(r.checkOtherAdmin(ctx, username)...)
+			if resp.Diagnostics.HasError() {
+				return
+			}
+		}
+		if err := r.client.UserSetAdmin(ctx, username, plan.Admin.ValueBool()); err != nil {
 			resp.Diagnostics.AddError("Error updating admin status", err.Error())
 			return
 		}
 	}
 
-	// Update public keys
-	if !plan.PublicKeys.Equal(state.PublicKeys) {
+	// Update public keys, unless they're only managed at creation
+	var keyFailures []string
+	if !plan.IgnoreKeyChanges.ValueBool() && !plan.PublicKeys.Equal(state.PublicKeys) {
 		var planKeys, stateKeys []string
 		if !plan.PublicKeys.IsNull() {
 			resp.Diagnostics.Append(plan.PublicKeys.ElementsAs(ctx, &planKeys, false)...)
@@ -167,36 +211,24 @@ func (r *UserResource) Update(ctx context.Context, req resource.UpdateRequest, r
 			return
 		}
 
-		planSet := toStringSet(planKeys)
-		stateSet := toStringSet(stateKeys)
-
-		// Remove keys no longer in plan
-		for key := range stateSet {
-			if _, ok := planSet[key]; !ok {
-				if err := r.client.UserRemovePublicKey(username, key); err != nil {
-					resp.Diagnostics.AddError("Error removing public key", err.Error())
-					return
-				}
-			}
-		}
-
-		// Add new keys
-		for key := range planSet {
-			if _, ok := stateSet[key]; !ok {
-				if err := r.client.UserAddPublicKey(username, key); err != nil {
-					resp.Diagnostics.AddError("Error adding public key", err.Error())
-					return
-				}
-			}
-		}
+		keyFailures = r.syncPublicKeys(ctx, username, planKeys, stateKeys)
 	}
 
+	// Record what the server ended up with even if some keys failed, so the
+	// next plan retries only those.
 	resp.Diagnostics.Append(r.readUserState(ctx, username, &plan)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+	resp.Diagnostics.Append(unparsedKeysWarning(ctx, username, plan.Fingerprints)...)
+
+	if len(keyFailures) > 0 {
+		resp.Diagnostics.AddError("Error updating public keys",
+			fmt.Sprintf("Some public keys of user %q could not be updated; the others were applied:\n\n%s",
+				username, strings.Join(keyFailures, "\n")))
+	}
 }
 
 func (r *UserResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
@@ -206,7 +238,23 @@ func (r *UserResource) Delete(ctx context.Context, req resource.DeleteRequest, r
 		return
 	}
 
-	if err := r.client.UserDelete(state.Username.ValueString()); err != nil {
+	username := state.Username.ValueString()
+
+	if !state.ForceDestroy.ValueBool() {
+		owned, err := r.ownedRepos(ctx, username)
+		if err != nil {
+			resp.Diagnostics.AddError("Error checking repository owners", err.Error())
+			return
+		}
+		if len(owned) > 0 {
+			resp.Diagnostics.AddError("User owns repositories",
+				fmt.Sprintf("User %q owns %s, which would be left without an owner. Transfer or delete them, or set force_destroy = true and apply before destroying the user.",
+					username, strings.Join(owned, ", ")))
+			return
+		}
+	}
+
+	if err := r.client.UserDelete(ctx, username); err != nil {
 		resp.Diagnostics.AddError("Error deleting user", err.Error())
 	}
 }
@@ -214,6 +262,8 @@ func (r *UserResource) Delete(ctx context.Context, req resource.DeleteRequest, r
 func (r *UserResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
 	var model UserResourceModel
 	model.Username = types.StringValue(req.ID)
+	model.IgnoreKeyChanges = types.BoolValue(false)
+	model.ForceDestroy = types.BoolValue(false)
 
 	resp.Diagnostics.Append(r.readUserState(ctx, req.ID, &model)...)
 	if resp.Diagnostics.HasError() {
@@ -226,7 +276,7 @@ func (r *UserResource) ImportState(ctx context.Context, req resource.ImportState
 func (r *UserResource) readUserState(ctx context.Context, username string, model *UserResourceModel) diag.Diagnostics {
 	var diags diag.Diagnostics
 
-	info, err := r.client.UserInfo(username)
+	info, err := r.client.UserInfo(ctx, username)
 	if err != nil {
 		diags.AddError("Error reading user", err.Error())
 		return diags
@@ -236,23 +286,57 @@ func (r *UserResource) readUserState(ctx context.Context, username string, model
 	model.Username = types.StringValue(info.Username)
 	model.Admin = types.BoolValue(info.Admin)
 
-	if len(info.PublicKeys) > 0 {
-		// Sort for deterministic state
-		sorted := make([]string, len(info.PublicKeys))
-		copy(sorted, info.PublicKeys)
-		sort.Strings(sorted)
+	// Soft Serve stores keys without their comments, so a key keeps the
+	// comment it is configured with, its title, for as long as the server
+	// has it.
+	titled := make(map[string]string)
+	if !model.PublicKeys.IsNull() && !model.PublicKeys.IsUnknown() {
+		var configured []string
+		diags.Append(model.PublicKeys.ElementsAs(ctx, &configured, false)...)
+		for _, k := range configured {
+			key, _ := ssh.SplitPublicKey(k)
+			titled[key] = k
+		}
+	}
+	keys := make([]string, len(info.PublicKeys))
+	for i, key := range info.PublicKeys {
+		switch {
+		case titled[key] != "":
+			keys[i] = titled[key]
+		case info.PublicKeyComments[i] != "":
+			keys[i] = key + " " + info.PublicKeyComments[i]
+		default:
+			keys[i] = key
+		}
+	}
 
-		keyValues := make([]types.String, len(sorted))
-		for i, k := range sorted {
-			keyValues[i] = types.StringValue(k)
+	// Keys are stored as a set built from sorted input, so the stored value
+	// is canonical no matter what order the server or configuration lists
+	// them in; fingerprints follow the same order.
+	sorted := slices.Sorted(slices.Values(keys))
+
+	// A key the server reports but that doesn't parse gets an empty
+	// fingerprint rather than failing every refresh; Create and Update warn
+	// about it.
+	fingerprints := make([]string, len(sorted))
+	for i, k := range sorted {
+		if fp, err := ssh.PublicKeyFingerprint(k); err == nil {
+			fingerprints[i] = fp
 		}
-		keySet, d := types.SetValueFrom(ctx, types.StringType, sorted)
-		diags.Append(d...)
-		model.PublicKeys = keySet
-	} else if !model.PublicKeys.IsNull() {
-		// Preserve null vs empty distinction: if plan had keys but server has none,
-		// set to empty set; if plan was null, keep null
-		keySet, d := types.SetValueFrom(ctx, types.StringType, []string{})
+	}
+	fpList, d := types.ListValueFrom(ctx, types.StringType, fingerprints)
+	diags.Append(d...)
+	model.Fingerprints = fpList
+
+	// With ignore_key_changes the stored keys are left as configured.
+	// Otherwise, preserve null vs empty: a user configured without
+	// public_keys keeps null when the server has none, anything else gets
+	// the server's set.
+	if model.IgnoreKeyChanges.ValueBool() {
+		return diags
+	}
+	if len(sorted) > 0 || !model.PublicKeys.IsNull() {
+		keySet, d := types.SetValueFrom(ctx, types.StringType, append([]string{}, sorted...))
 		diags.Append(d...)
 		model.PublicKeys = keySet
 	}
@@ -260,6 +344,181 @@ func (r *UserResource) readUserState(ctx context.Context, username string, model
 	return diags
 }
 
+// unparsedKeysWarning warns when fingerprints has empty entries, for keys the
+// server reports that couldn't be parsed. It's only given on Create and
+// Update so that refreshing doesn't repeat it.
+func unparsedKeysWarning(ctx context.Context, username string, fingerprints types.List) diag.Diagnostics {
+	var diags diag.Diagnostics
+	var fps []string
+	diags.Append(fingerprints.ElementsAs(ctx, &fps, false)...)
+	n := 0
+	for _, fp := range fps {
+		if fp == "" {
+			n++
+		}
+	}
+	if n > 0 {
+		diags.AddAttributeWarning(path.Root("fingerprints"), "Unable to fingerprint public keys",
+			fmt.Sprintf("User %q has %d public key(s) on the server that couldn't be parsed; their fingerprints are empty.", username, n))
+	}
+	return diags
+}
+
+// checkOtherAdmin fails unless some user other than username is an admin, so
+// that demoting username can't leave the server without one.
+func (r *UserResource) checkOtherAdmin(ctx context.Context, username string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	users, err := r.client.UserList(ctx)
+	if err != nil {
+		diags.AddError("Error listing users", err.Error())
+		return diags
+	}
+	for _, u := range users {
+		if strings.EqualFold(u, username) {
+			continue
+		}
+		info, err := r.client.UserInfo(ctx, u)
+		if err != nil {
+			diags.AddError("Error reading user", err.Error())
+			return diags
+		}
+		if info.Admin {
+			return diags
+		}
+	}
+
+	diags.AddAttributeError(path.Root("admin"), "Cannot demote the last admin",
+		fmt.Sprintf("User %q is the only admin on the server, so demoting it could lock everyone out. Make another user an admin first, or set protect_last_admin = false in the provider configuration.", username))
+	return diags
+}
+
+// keyConflict reports which of keys are registered to other users, for a
+// user create that failed because keys are unique across the server. It
+// returns no error diagnostics when no holder is found, e.g. because the
+// username was the duplicate, so the caller can report the original error.
+func (r *UserResource) keyConflict(ctx context.Context, keys []string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	users, err := r.client.UserList(ctx)
+	if err != nil {
+		return diags
+	}
+	want := make(map[string]string, len(keys))
+	for _, key := range keys {
+		want[keyLabel(key)] = key
+	}
+	for _, u := range users {
+		info, err := r.client.UserInfo(ctx, u)
+		if err != nil {
+			continue
+		}
+		for _, key := range info.PublicKeys {
+			label := keyLabel(key)
+			if _, ok := want[label]; !ok {
+				continue
+			}
+			diags.AddAttributeError(path.Root("public_keys"), "Public key already registered",
+				fmt.Sprintf("Public key %s is already registered to user %q. Soft Serve requires every key to be unique across users; remove it from that user or from this configuration.", label, info.Username))
+			delete(want, label)
+		}
+	}
+	return diags
+}
+
+// ownedRepos returns the sorted names of the repositories username owns.
+// Soft Serve can't list repositories by owner, so each one is read, several
+// at a time; one deleted during the scan is skipped.
+func (r *UserResource) ownedRepos(ctx context.Context, username string) ([]string, error) {
+	repos, err := r.client.RepoList(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		mu    sync.Mutex
+		owned []string
+		errs  []error
+		wg    sync.WaitGroup
+	)
+	names := make(chan string)
+	for range min(ownerScanWorkers, len(repos)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for name := range names {
+				info, err := r.client.RepoInfo(ctx, name)
+				mu.Lock()
+				switch {
+				case err == nil && strings.EqualFold(info.Owner, username):
+					owned = append(owned, info.Repository)
+				case err != nil && !ssh.IsNotFound(err):
+					errs = append(errs, err)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, name := range repos {
+		names <- name
+	}
+	close(names)
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	slices.Sort(owned)
+	return owned, nil
+}
+
+// syncPublicKeys removes the keys in stateKeys that aren't in planKeys and
+// adds the ones that are new. Keys are compared without their comments,
+// which the server doesn't store, so retitling a key changes nothing. A
+// failing key doesn't stop the others; instead each failure is described in
+// the returned list.
+func (r *UserResource) syncPublicKeys(ctx context.Context, username string, planKeys, stateKeys []string) []string {
+	planSet := toStringSet(withoutComments(planKeys))
+	stateSet := toStringSet(withoutComments(stateKeys))
+
+	var failures []string
+	for _, key := range slices.Sorted(maps.Keys(stateSet)) {
+		if _, ok := planSet[key]; ok {
+			continue
+		}
+		if err := r.client.UserRemovePublicKey(ctx, username, key); err != nil {
+			failures = append(failures, fmt.Sprintf("- removing %s: %s", keyLabel(key), err))
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(planSet)) {
+		if _, ok := stateSet[key]; ok {
+			continue
+		}
+		if err := r.client.UserAddPublicKey(ctx, username, key); err != nil {
+			failures = append(failures, fmt.Sprintf("- adding %s: %s", keyLabel(key), err))
+		}
+	}
+	return failures
+}
+
+// keyLabel identifies a public key in messages by its fingerprint, falling
+// back to the key itself when it can't be parsed.
+func keyLabel(key string) string {
+	if fp, err := ssh.PublicKeyFingerprint(key); err == nil {
+		return fp
+	}
+	return key
+}
+
+// withoutComments returns keys with each one's comment removed.
+func withoutComments(keys []string) []string {
+	stripped := make([]string, len(keys))
+	for i, k := range keys {
+		stripped[i], _ = ssh.SplitPublicKey(k)
+	}
+	return stripped
+}
+
 func toStringSet(s []string) map[string]struct{} {
 	m := make(map[string]struct{}, len(s))
 	for _, v := range s {
@@ -0,0 +1,52 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	pathpkg "path"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+
+	"github.com/ssoriche/terraform-provider-soft-serve/internal/ssh"
+)
+
+// Length limits for free-text repository attributes, checked at plan time
+// so oversized values fail early instead of at the server.
+const (
+	MaxDescriptionLength = 1024
+	MaxProjectNameLength = 255
+)
+
+// AccessLevelValidator validates that a string is one of the Soft Serve
+// access levels in ssh.AccessLevels.
+func AccessLevelValidator() validator.String {
+	return stringvalidator.OneOf(ssh.AccessLevelStrings()...)
+}
+
+// GlobPatternValidator validates that a string is a pattern path.Match
+// accepts, e.g. "bot-*" or "ci-[0-9]".
+func GlobPatternValidator() validator.String {
+	return globPatternValidator{}
+}
+
+type globPatternValidator struct{}
+
+func (v globPatternValidator) Description(_ context.Context) string {
+	return "value must be a glob pattern using *, ? and [...]"
+}
+
+func (v globPatternValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v globPatternValidator) ValidateString(_ context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+	pattern := req.ConfigValue.ValueString()
+	if _, err := pathpkg.Match(pattern, ""); err != nil {
+		resp.Diagnostics.AddAttributeError(req.Path, "Invalid glob pattern",
+			fmt.Sprintf("%q is not a valid glob pattern: %s", pattern, err))
+	}
+}
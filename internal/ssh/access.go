@@ -0,0 +1,74 @@
+package ssh
+
+import "strings"
+
+// AccessLevel is a Soft Serve access level, as used for repository
+// collaborators and anonymous access.
+type AccessLevel string
+
+// Access levels understood by Soft Serve.
+const (
+	AccessLevelNoAccess    AccessLevel = "no-access"
+	AccessLevelReadOnly    AccessLevel = "read-only"
+	AccessLevelReadWrite   AccessLevel = "read-write"
+	AccessLevelAdminAccess AccessLevel = "admin-access"
+)
+
+// AccessLevels lists every valid access level, from least to most privileged.
+var AccessLevels = []AccessLevel{
+	AccessLevelNoAccess,
+	AccessLevelReadOnly,
+	AccessLevelReadWrite,
+	AccessLevelAdminAccess,
+}
+
+// Valid reports whether a is a known access level.
+func (a AccessLevel) Valid() bool {
+	for _, l := range AccessLevels {
+		if a == l {
+			return true
+		}
+	}
+	return false
+}
+
+// AccessLevelStrings returns AccessLevels as plain strings.
+func AccessLevelStrings() []string {
+	s := make([]string, len(AccessLevels))
+	for i, l := range AccessLevels {
+		s[i] = string(l)
+	}
+	return s
+}
+
+// accessLevelAliases maps spellings seen from older or differently configured
+// servers to their canonical access level.
+var accessLevelAliases = map[string]AccessLevel{
+	"0":           AccessLevelNoAccess,
+	"none":        AccessLevelNoAccess,
+	"noaccess":    AccessLevelNoAccess,
+	"1":           AccessLevelReadOnly,
+	"read":        AccessLevelReadOnly,
+	"readonly":    AccessLevelReadOnly,
+	"2":           AccessLevelReadWrite,
+	"write":       AccessLevelReadWrite,
+	"readwrite":   AccessLevelReadWrite,
+	"3":           AccessLevelAdminAccess,
+	"admin":       AccessLevelAdminAccess,
+	"adminaccess": AccessLevelAdminAccess,
+}
+
+// NormalizeAccessLevel maps an access level reported by a server to its
+// canonical form, tolerating differences in case, separators and a few
+// legacy aliases. It reports false when s matches no known level.
+func NormalizeAccessLevel(s string) (AccessLevel, bool) {
+	key := strings.ToLower(strings.TrimSpace(s))
+	key = strings.NewReplacer("_", "-", " ", "-").Replace(key)
+	if l := AccessLevel(key); l.Valid() {
+		return l, true
+	}
+	if l, ok := accessLevelAliases[strings.ReplaceAll(key, "-", "")]; ok {
+		return l, true
+	}
+	return "", false
+}
@@ -0,0 +1,56 @@
+package ssh
+
+import "testing"
+
+func TestAccessLevelValid(t *testing.T) {
+	for _, l := range AccessLevels {
+		if !l.Valid() {
+			t.Errorf("%q should be valid", l)
+		}
+	}
+	for _, l := range []AccessLevel{"", "read", "Read-Only", "admin"} {
+		if l.Valid() {
+			t.Errorf("%q should not be valid", l)
+		}
+	}
+}
+
+func TestAccessLevelStrings(t *testing.T) {
+	want := []string{"no-access", "read-only", "read-write", "admin-access"}
+	got := AccessLevelStrings()
+	if len(got) != len(want) {
+		t.Fatalf("got %d levels, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestNormalizeAccessLevel(t *testing.T) {
+	tests := []struct {
+		in     string
+		want   AccessLevel
+		wantOK bool
+	}{
+		{"read-only", AccessLevelReadOnly, true},
+		{"Read-Only", AccessLevelReadOnly, true},
+		{" admin_access\n", AccessLevelAdminAccess, true},
+		{"no access", AccessLevelNoAccess, true},
+		{"readwrite", AccessLevelReadWrite, true},
+		{"read", AccessLevelReadOnly, true},
+		{"admin", AccessLevelAdminAccess, true},
+		{"0", AccessLevelNoAccess, true},
+		{"3", AccessLevelAdminAccess, true},
+		{"", "", false},
+		{"superuser", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := NormalizeAccessLevel(tt.in)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("NormalizeAccessLevel(%q) = %q, %v; want %q, %v", tt.in, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
@@ -2,239 +2,1156 @@ package ssh
 
 import (
 	"bytes"
+	"context"
+	"encoding/pem"
+	"errors"
 	"fmt"
+	"io"
+	"maps"
+	"math/rand/v2"
 	"net"
+	"net/url"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"golang.org/x/crypto/ssh"
 	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+	"golang.org/x/time/rate"
 )
 
-// Client manages SSH connections to a Soft Serve instance.
+// Client manages SSH connections to a Soft Serve instance. A connection is
+// dialed on first use and shared by every command; when commands run
+// concurrently, further connections are opened up to a configured maximum
+// and each command uses the least busy one. A Client is safe for concurrent
+// use.
 type Client struct {
 	host      string
 	port      int
 	username  string
-	signer    ssh.Signer
+	signers   []ssh.Signer // configured private keys, offered in order
 	agentConn net.Conn
-	agentAuth ssh.AuthMethod
+	agentErr  error // why the requested SSH agent couldn't be used
+	prefix    string
+	subsystem string
+	verbose   bool              // report failed commands unredacted
+	env       map[string]string // set on each session
+	version   string            // client identification string; empty uses the library's
+
+	proxyCommand string
+	unixSocket   string
+	httpBaseURL  string // no trailing slash; empty when unset
+
+	protectLastAdmin bool
+
+	maxRetries     int
+	retryBaseDelay time.Duration
+	retryMaxDelay  time.Duration
+
+	bannerTimeout time.Duration // zero for no limit
+	authTimeout   time.Duration // zero for no limit
+
+	limiter *rate.Limiter // paces commands; nil when unlimited
+
+	// agentSigners lists the agent keys to offer; nil when the agent is unused.
+	agentSigners func() ([]ssh.Signer, error)
+	// agentKeys caches the result of agentSigners after the first dial so
+	// that redials don't query the agent again. Guarded by mu.
+	agentKeys []ssh.Signer
+
+	hostKeyCallback ssh.HostKeyCallback
+
+	maxConns int // connections to open at most; at least 1
+
+	mu       sync.Mutex          // guards conns, dialing, dialDone and agentConn
+	conns    map[*ssh.Client]int // open connections and their commands in flight
+	dialing  int                 // connections being dialed, counted against maxConns
+	dialDone chan struct{}       // closed, and cleared, when a dial finishes; nil when nobody waits
 }
 
 // ClientConfig holds configuration for creating a new SSH client.
 type ClientConfig struct {
-	Host           string
-	Port           int
-	Username       string
-	PrivateKey     string // PEM-encoded private key contents
-	PrivateKeyPath string // Path to private key file
-	UseAgent       bool
-	IdentityFile   string // Path to public key file to filter agent keys
+	Host                string
+	Port                int
+	Username            string
+	PrivateKey          string // PEM-encoded private key contents; may hold several concatenated keys
+	PrivateKeyPath      string // Path to private key file, which may also hold several keys
+	UseAgent            bool
+	AgentExplicit       bool     // UseAgent was set by the user rather than defaulted
+	IdentitiesOnly      bool     // Never consult the agent when a private key is loaded, like OpenSSH's IdentitiesOnly
+	IdentityFiles       []string // Paths to public key files to filter agent keys, in order of preference
+	IdentityFingerprint string   // SHA256 fingerprint of an agent key to offer, checked after IdentityFiles
+	KnownHostsFile      string   // Path to known_hosts file for host key verification
+	CommandPrefix       string   // Prepended to every command, e.g. a forced-command wrapper
+	Subsystem           string   // SSH subsystem to send commands to instead of an exec request; empty uses exec
+	HTTPBaseURL         string   // Base URL of the server's HTTP endpoint, used for HTTP clone URLs
+	ProtectLastAdmin    bool     // Resources refuse to demote the server's last admin
+	VerboseErrors       bool     // Don't redact possible secrets from CommandError messages
+	ClientVersion       string   // SSH identification string sent to the server; must start with "SSH-2.0-"
+
+	// Env holds environment variables to set on each session, for forced
+	// command wrappers that read them. Servers commonly refuse variables not
+	// listed in their AcceptEnv; refusals are logged and otherwise ignored.
+	Env map[string]string
+
+	// ProxyCommand, when set, is run through the shell and its stdin and
+	// stdout used as the connection instead of dialing TCP, like OpenSSH's
+	// ProxyCommand. %h, %p and %r expand to the host, port and username.
+	ProxyCommand string
+
+	// UnixSocket, when set, is the path of a Unix domain socket to connect
+	// to instead of dialing Host and Port over TCP. Host and Port are still
+	// used to look up the server's host key.
+	UnixSocket string
+
+	// Connection retry settings. Only failures to open the TCP connection are
+	// retried; zero MaxRetries disables retrying.
+	MaxRetries     int
+	RetryBaseDelay time.Duration // Defaults to DefaultRetryBaseDelay
+	RetryMaxDelay  time.Duration // Defaults to DefaultRetryMaxDelay
+
+	// BannerTimeout limits how long to wait, once connected, for the server
+	// to identify itself. AuthTimeout limits the whole handshake, including
+	// key exchange and authentication. Zero means no limit.
+	BannerTimeout time.Duration
+	AuthTimeout   time.Duration
+
+	// CommandsPerSecond, when positive, limits how fast commands are sent so
+	// that bursts from a wide apply don't trip server rate limits.
+	CommandsPerSecond float64
+
+	// MaxConnections is the number of connections that may be open at once
+	// for commands running concurrently, such as Terraform's parallel
+	// resource operations. Values below 1 mean 1.
+	MaxConnections int
 }
 
+// Default connection retry delays used when ClientConfig leaves them unset.
+const (
+	DefaultRetryBaseDelay = 250 * time.Millisecond
+	DefaultRetryMaxDelay  = 5 * time.Second
+)
+
+// Handshake timeouts the provider uses unless configured otherwise.
+const (
+	DefaultBannerTimeout = 15 * time.Second
+	DefaultAuthTimeout   = 30 * time.Second
+)
+
 // NewClient creates a new SSH client for Soft Serve.
 func NewClient(cfg ClientConfig) (*Client, error) {
 	c := &Client{
-		host:     cfg.Host,
-		port:     cfg.Port,
-		username: cfg.Username,
+		host:      cfg.Host,
+		port:      cfg.Port,
+		username:  cfg.Username,
+		prefix:    strings.TrimSpace(cfg.CommandPrefix),
+		subsystem: strings.TrimSpace(cfg.Subsystem),
+		verbose:   cfg.VerboseErrors,
+		env:       maps.Clone(cfg.Env),
+		version:   cfg.ClientVersion,
+
+		proxyCommand: strings.TrimSpace(cfg.ProxyCommand),
+		unixSocket:   cfg.UnixSocket,
+		httpBaseURL:  strings.TrimRight(strings.TrimSpace(cfg.HTTPBaseURL), "/"),
+
+		protectLastAdmin: cfg.ProtectLastAdmin,
+
+		maxRetries:     cfg.MaxRetries,
+		retryBaseDelay: cfg.RetryBaseDelay,
+		retryMaxDelay:  cfg.RetryMaxDelay,
+
+		bannerTimeout: cfg.BannerTimeout,
+		authTimeout:   cfg.AuthTimeout,
+
+		maxConns: max(cfg.MaxConnections, 1),
+	}
+	if c.retryBaseDelay <= 0 {
+		c.retryBaseDelay = DefaultRetryBaseDelay
+	}
+	if c.retryMaxDelay <= 0 {
+		c.retryMaxDelay = DefaultRetryMaxDelay
+	}
+	if c.retryMaxDelay < c.retryBaseDelay {
+		c.retryMaxDelay = c.retryBaseDelay
+	}
+	if cfg.CommandsPerSecond > 0 {
+		c.limiter = rate.NewLimiter(rate.Limit(cfg.CommandsPerSecond), 1)
+	}
+	if c.version != "" && !strings.HasPrefix(c.version, "SSH-2.0-") {
+		return nil, fmt.Errorf("client version %q must start with \"SSH-2.0-\"", c.version)
+	}
+	if c.proxyCommand != "" && c.unixSocket != "" {
+		return nil, fmt.Errorf("a proxy command and a Unix socket can't both be used")
 	}
 
 	// Try private key first (takes precedence)
 	if cfg.PrivateKey != "" {
-		signer, err := ssh.ParsePrivateKey([]byte(cfg.PrivateKey))
+		signers, err := parsePrivateKeys([]byte(cfg.PrivateKey))
 		if err != nil {
 			return nil, fmt.Errorf("parsing private key: %w", err)
 		}
-		c.signer = signer
+		c.signers = signers
 	} else if cfg.PrivateKeyPath != "" {
 		keyData, err := os.ReadFile(cfg.PrivateKeyPath)
 		if err != nil {
 			return nil, fmt.Errorf("reading private key file %s: %w", cfg.PrivateKeyPath, err)
 		}
-		signer, err := ssh.ParsePrivateKey(keyData)
+		signers, err := parsePrivateKeys(keyData)
 		if err != nil {
 			return nil, fmt.Errorf("parsing private key from %s: %w", cfg.PrivateKeyPath, err)
 		}
-		c.signer = signer
+		c.signers = signers
 	}
 
-	// Set up SSH agent if requested
-	if cfg.UseAgent {
+	// Set up SSH agent if requested. A successfully loaded private key takes
+	// precedence: the agent is then only consulted when UseAgent was set
+	// explicitly, since offering every agent key on top of the configured one
+	// can trip the server's MaxAuthTries limit, and never with IdentitiesOnly.
+	if cfg.UseAgent && (c.signers == nil || (cfg.AgentExplicit && !cfg.IdentitiesOnly)) {
 		socket := os.Getenv("SSH_AUTH_SOCK")
-		if socket != "" {
+		if socket == "" {
+			c.agentErr = errors.New("SSH_AUTH_SOCK is not set")
+		} else {
 			conn, err := net.Dial("unix", socket)
-			if err == nil {
+			if err != nil {
+				c.agentErr = fmt.Errorf("opening SSH agent socket %s from SSH_AUTH_SOCK: %w", socket, err)
+			} else {
 				c.agentConn = conn
 				agentClient := agent.NewClient(conn)
-				if cfg.IdentityFile != "" {
-					c.agentAuth, err = filteredAgentAuth(agentClient, cfg.IdentityFile)
+				if len(cfg.IdentityFiles) > 0 || cfg.IdentityFingerprint != "" {
+					c.agentSigners, err = filteredAgentSigners(agentClient, cfg.IdentityFiles, cfg.IdentityFingerprint)
 					if err != nil {
 						_ = conn.Close()
-						return nil, fmt.Errorf("filtering agent keys with identity file: %w", err)
+						return nil, fmt.Errorf("filtering agent keys: %w", err)
 					}
 				} else {
-					c.agentAuth = ssh.PublicKeysCallback(agentClient.Signers)
+					c.agentSigners = agentClient.Signers
 				}
 			}
 		}
 	}
 
-	if c.signer == nil && c.agentAuth == nil {
+	if c.signers == nil && c.agentSigners == nil {
+		if c.agentErr != nil {
+			return nil, fmt.Errorf("no authentication method available: provide a private key or fix the SSH agent: %w", c.agentErr)
+		}
 		return nil, fmt.Errorf("no authentication method available: provide a private key or enable SSH agent")
 	}
 
+	// Verify host keys only when a known_hosts file is configured
+	if cfg.KnownHostsFile != "" {
+		callback, err := knownhosts.New(cfg.KnownHostsFile)
+		if err != nil {
+			_ = c.Close()
+			return nil, fmt.Errorf("loading known hosts file %s: %w", cfg.KnownHostsFile, err)
+		}
+		c.hostKeyCallback = callback
+	} else {
+		c.hostKeyCallback = ssh.InsecureIgnoreHostKey() //nolint:gosec // opt-in verification via KnownHostsFile
+	}
+
 	return c, nil
 }
 
-// Close cleans up any resources held by the client.
+// AgentError returns why the SSH agent couldn't be used although it was
+// requested, such as SSH_AUTH_SOCK naming a socket that can't be opened, or
+// nil. Other keys may still have been loaded.
+func (c *Client) AgentError() error {
+	return c.agentErr
+}
+
+// Close closes every open connection and the SSH agent socket, if open.
 func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var errs []error
+	for conn := range c.conns {
+		errs = append(errs, conn.Close())
+	}
+	c.conns = nil
 	if c.agentConn != nil {
-		return c.agentConn.Close()
+		errs = append(errs, c.agentConn.Close())
+		c.agentConn = nil
 	}
-	return nil
+	return errors.Join(errs...)
 }
 
-// filteredAgentAuth reads a public key from identityFile and returns an
-// AuthMethod that only offers the matching key from the SSH agent. This
-// mirrors OpenSSH's IdentityFile behavior when used with an agent.
-func filteredAgentAuth(agentClient agent.ExtendedAgent, identityFile string) (ssh.AuthMethod, error) {
-	pubKeyData, err := os.ReadFile(identityFile)
-	if err != nil {
-		return nil, fmt.Errorf("reading identity file %s: %w", identityFile, err)
+// SSHCloneURL returns the URL for cloning repository name over SSH from the
+// configured host and port.
+func (c *Client) SSHCloneURL(name string) string {
+	return "ssh://" + net.JoinHostPort(c.host, strconv.Itoa(c.port)) + "/" + name
+}
+
+// HTTPCloneURL returns the URL for cloning repository name over HTTP, or ""
+// when no HTTP base URL is configured.
+func (c *Client) HTTPCloneURL(name string) string {
+	if c.httpBaseURL == "" {
+		return ""
+	}
+	return c.httpBaseURL + "/" + name + ".git"
+}
+
+// ProtectsLastAdmin reports whether resources should refuse to demote the
+// server's last remaining admin.
+func (c *Client) ProtectsLastAdmin() bool {
+	return c.protectLastAdmin
+}
+
+// parsePrivateKeys parses every PEM-encoded private key in data, which may
+// hold several concatenated keys, e.g. while rotating them. Keys that fail to
+// parse are skipped as long as at least one parses; otherwise the first error
+// is returned.
+func parsePrivateKeys(data []byte) ([]ssh.Signer, error) {
+	var signers []ssh.Signer
+	var firstErr error
+	// Keys joined without a newline between them still split into blocks
+	for rest := bytes.ReplaceAll(data, []byte("-----BEGIN "), []byte("\n-----BEGIN ")); ; {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		signer, err := ssh.ParsePrivateKey(pem.EncodeToMemory(block))
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		signers = append(signers, signer)
+	}
+	if len(signers) > 0 {
+		return signers, nil
 	}
-	wantKey, _, _, _, err := ssh.ParseAuthorizedKey(pubKeyData)
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	// Without any PEM block, let the parser say what's wrong with data
+	signer, err := ssh.ParsePrivateKey(data)
 	if err != nil {
-		return nil, fmt.Errorf("parsing public key from %s: %w", identityFile, err)
+		return nil, err
 	}
-	wantBytes := wantKey.Marshal()
+	return []ssh.Signer{signer}, nil
+}
 
-	return ssh.PublicKeysCallback(func() ([]ssh.Signer, error) {
+// filteredAgentSigners returns a signer source that yields only the first
+// agent key matching one of the public keys in identityFiles, checked in the
+// order the files are listed, or else the agent key with the given SHA256
+// fingerprint. This mirrors OpenSSH's IdentityFile behavior when used with an
+// agent, for keys whose public key file isn't at hand.
+func filteredAgentSigners(agentClient agent.Agent, identityFiles []string, fingerprint string) (func() ([]ssh.Signer, error), error) {
+	wantKeys := make([][]byte, 0, len(identityFiles))
+	for _, identityFile := range identityFiles {
+		pubKeyData, err := os.ReadFile(identityFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading identity file %s: %w", identityFile, err)
+		}
+		wantKey, _, _, _, err := ssh.ParseAuthorizedKey(pubKeyData)
+		if err != nil {
+			return nil, fmt.Errorf("parsing public key from %s: %w", identityFile, err)
+		}
+		wantKeys = append(wantKeys, wantKey.Marshal())
+	}
+	fingerprint = strings.TrimSpace(fingerprint)
+	if fingerprint != "" && !strings.HasPrefix(fingerprint, "SHA256:") {
+		fingerprint = "SHA256:" + fingerprint
+	}
+
+	var selectors []string
+	if len(identityFiles) > 0 {
+		selectors = append(selectors, "identity files "+strings.Join(identityFiles, ", "))
+	}
+	if fingerprint != "" {
+		selectors = append(selectors, "fingerprint "+fingerprint)
+	}
+
+	return func() ([]ssh.Signer, error) {
 		signers, err := agentClient.Signers()
 		if err != nil {
 			return nil, err
 		}
-		for _, s := range signers {
-			if bytes.Equal(s.PublicKey().Marshal(), wantBytes) {
-				return []ssh.Signer{s}, nil
+		for _, wantBytes := range wantKeys {
+			for _, s := range signers {
+				if bytes.Equal(s.PublicKey().Marshal(), wantBytes) {
+					return []ssh.Signer{s}, nil
+				}
 			}
 		}
-		return nil, fmt.Errorf("identity file %s: matching key not found in SSH agent", identityFile)
-	}), nil
+		if fingerprint != "" {
+			for _, s := range signers {
+				if ssh.FingerprintSHA256(s.PublicKey()) == fingerprint {
+					return []ssh.Signer{s}, nil
+				}
+			}
+		}
+		return nil, fmt.Errorf("%s: no matching key found in SSH agent", strings.Join(selectors, " or "))
+	}, nil
 }
 
-// Run executes a command on the Soft Serve server and returns stdout.
-func (c *Client) Run(command string) (string, error) {
-	var authMethods []ssh.AuthMethod
-	if c.signer != nil {
-		authMethods = append(authMethods, ssh.PublicKeys(c.signer))
+// oneKeyPerAttempt returns an AuthMethod that offers signers one at a time,
+// each as its own publickey attempt, instead of presenting the whole list in
+// a single attempt. Like OpenSSH, this stops at the first accepted key rather
+// than letting a large agent exhaust the server's MaxAuthTries up front.
+func oneKeyPerAttempt(signers []ssh.Signer) ssh.AuthMethod {
+	next := 0
+	return ssh.RetryableAuthMethod(ssh.PublicKeysCallback(func() ([]ssh.Signer, error) {
+		if next >= len(signers) {
+			return nil, nil
+		}
+		s := signers[next]
+		next++
+		return []ssh.Signer{s}, nil
+	}), len(signers))
+}
+
+// Run executes a command on the Soft Serve server and returns stdout with
+// trailing newlines removed. The configured command prefix, if any, is
+// prepended to command.
+func (c *Client) Run(ctx context.Context, command string) (string, error) {
+	output, err := c.RunRawOutput(ctx, command)
+	if err != nil {
+		return "", err
 	}
-	if c.agentAuth != nil {
-		authMethods = append(authMethods, c.agentAuth)
+	return strings.TrimRight(output, "\n"), nil
+}
+
+// RunRawOutput is like Run but returns stdout unmodified, for commands such
+// as `repo blob` whose trailing whitespace is part of the content.
+func (c *Client) RunRawOutput(ctx context.Context, command string) (string, error) {
+	result, err := c.RunOutput(ctx, command)
+	if err != nil {
+		return "", err
+	}
+	return result.Stdout, nil
+}
+
+// RunResult is the output of a command that succeeded.
+type RunResult struct {
+	Stdout string // Unmodified stdout
+	Stderr string // Trimmed stderr, such as warnings; usually empty
+}
+
+// RunOutput is like RunRawOutput but also returns what the command wrote to
+// stderr, for callers that want to surface warnings from commands that
+// succeeded. Such output is logged as a warning either way.
+func (c *Client) RunOutput(ctx context.Context, command string) (RunResult, error) {
+	if c.limiter != nil {
+		if err := c.limiter.Wait(ctx); err != nil {
+			return RunResult{}, fmt.Errorf("waiting to send command: %w", err)
+		}
+	}
+
+	if c.prefix != "" {
+		command = c.prefix + " " + command
+	}
+
+	for redialed := false; ; redialed = true {
+		conn, err := c.connect(ctx)
+		if err != nil {
+			return RunResult{}, err
+		}
+
+		var stdout, stderr bytes.Buffer
+		if c.subsystem != "" {
+			err = runSubsystem(ctx, conn, c.env, c.subsystem, command, &stdout, &stderr)
+		} else {
+			err = runExec(ctx, conn, c.env, command, &stdout, &stderr)
+		}
+		c.release(conn)
+		if err == nil {
+			result := RunResult{Stdout: stdout.String(), Stderr: strings.TrimSpace(stderr.String())}
+			if result.Stderr != "" {
+				logged, msg := command, result.Stderr
+				if !c.verbose {
+					logged, msg = redact(logged), redact(msg)
+				}
+				tflog.Warn(ctx, "Soft Serve command succeeded with a message on stderr", map[string]any{
+					"command": logged,
+					"stderr":  msg,
+				})
+			}
+			return result, nil
+		}
+
+		var sessErr *sessionError
+		if !errors.As(err, &sessErr) {
+			return RunResult{}, &CommandError{Command: command, Stderr: strings.TrimSpace(stderr.String()), Err: err, Verbose: c.verbose}
+		}
+		// The connection is likely dead; drop it so the next attempt redials.
+		c.disconnect(conn)
+		// A connection closed under us, e.g. by a server restart, is redialed
+		// once. The session was never opened, so the command didn't run.
+		if redialed || !connectionLost(sessErr.err) {
+			return RunResult{}, sessErr.err
+		}
+		tflog.Debug(ctx, "Soft Serve SSH connection lost, redialing", map[string]any{"error": sessErr.err.Error()})
+	}
+}
+
+// connectionLost reports whether err means the connection was closed, as
+// opposed to the server refusing a session on a live connection.
+func connectionLost(err error) bool {
+	return errors.Is(err, io.EOF) || errors.Is(err, net.ErrClosed) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE)
+}
+
+// sessionError reports a failure to open a session channel, as opposed to a
+// command that ran and failed.
+type sessionError struct{ err error }
+
+func (e *sessionError) Error() string { return e.err.Error() }
+
+// runExec runs command with an exec request on a new session.
+func runExec(ctx context.Context, conn *ssh.Client, env map[string]string, command string, stdout, stderr io.Writer) error {
+	session, err := conn.NewSession()
+	if err != nil {
+		return &sessionError{fmt.Errorf("creating session: %w", err)}
+	}
+	defer func() { _ = session.Close() }()
+
+	sendEnv(ctx, env, session.Setenv)
+
+	session.Stdout = stdout
+	session.Stderr = stderr
+	return session.Run(command)
+}
+
+// runSubsystem starts the named subsystem on a new session channel and writes
+// command to it as a single line on stdin. ssh.Session cannot wait on a
+// subsystem, so the channel is driven directly to collect the exit status.
+func runSubsystem(ctx context.Context, conn *ssh.Client, env map[string]string, subsystem, command string, stdout, stderr io.Writer) error {
+	ch, reqs, err := conn.OpenChannel("session", nil)
+	if err != nil {
+		return &sessionError{fmt.Errorf("creating session: %w", err)}
+	}
+	defer func() { _ = ch.Close() }()
+
+	exitStatus := make(chan int, 1)
+	go func() {
+		status := -1
+		for req := range reqs {
+			if req.Type == "exit-status" {
+				var msg struct{ Status uint32 }
+				if ssh.Unmarshal(req.Payload, &msg) == nil {
+					status = int(msg.Status)
+				}
+			}
+			if req.WantReply {
+				_ = req.Reply(false, nil)
+			}
+		}
+		exitStatus <- status
+	}()
+
+	sendEnv(ctx, env, func(name, value string) error {
+		ok, err := ch.SendRequest("env", true, ssh.Marshal(struct{ Name, Value string }{name, value}))
+		if err == nil && !ok {
+			err = errors.New("request rejected")
+		}
+		return err
+	})
+
+	ok, err := ch.SendRequest("subsystem", true, ssh.Marshal(struct{ Name string }{subsystem}))
+	if err == nil && !ok {
+		err = errors.New("request rejected")
 	}
+	if err != nil {
+		return fmt.Errorf("requesting subsystem %q: %w", subsystem, err)
+	}
+
+	if _, err := io.WriteString(ch, command+"\n"); err != nil {
+		return fmt.Errorf("writing command to subsystem %q: %w", subsystem, err)
+	}
+	_ = ch.CloseWrite()
+
+	stderrDone := make(chan error, 1)
+	go func() {
+		_, err := io.Copy(stderr, ch.Stderr())
+		stderrDone <- err
+	}()
+	if _, err := io.Copy(stdout, ch); err != nil {
+		return fmt.Errorf("reading subsystem output: %w", err)
+	}
+	if err := <-stderrDone; err != nil {
+		return fmt.Errorf("reading subsystem output: %w", err)
+	}
+	// The server closes the channel after the exit status, which ends reqs.
+	_ = ch.Close()
+
+	switch status := <-exitStatus; status {
+	case 0:
+		return nil
+	case -1:
+		return errors.New("subsystem exited without an exit status")
+	default:
+		return fmt.Errorf("subsystem exited with status %d", status)
+	}
+}
+
+// sendEnv sets each variable in env with setenv, in name order. A refused
+// variable is only logged, since servers commonly accept none.
+func sendEnv(ctx context.Context, env map[string]string, setenv func(name, value string) error) {
+	for _, name := range slices.Sorted(maps.Keys(env)) {
+		if err := setenv(name, env[name]); err != nil {
+			tflog.Warn(ctx, "Soft Serve SSH server did not accept environment variable; check its AcceptEnv", map[string]any{
+				"name":  name,
+				"error": err.Error(),
+			})
+		}
+	}
+}
+
+// connect checks out a connection for one command, which the caller must
+// release. An idle connection is reused; when every open one is busy, another
+// is dialed until maxConns are open or being dialed, after which the least
+// busy is shared. The lock isn't held while dialing: the dial reserves its
+// slot, and callers finding no connection to share wait for a dial to finish
+// instead of each opening their own, giving up when their ctx is done.
+func (c *Client) connect(ctx context.Context) (*ssh.Client, error) {
+	c.mu.Lock()
+	for {
+		var least *ssh.Client
+		for conn, n := range c.conns {
+			if least == nil || n < c.conns[least] {
+				least = conn
+			}
+		}
+		full := len(c.conns)+c.dialing >= max(c.maxConns, 1)
+		if least != nil && (c.conns[least] == 0 || full) {
+			c.conns[least]++
+			c.mu.Unlock()
+			return least, nil
+		}
+		if !full {
+			break
+		}
+
+		// Every slot is taken by a dial in progress
+		if c.dialDone == nil {
+			c.dialDone = make(chan struct{})
+		}
+		done := c.dialDone
+		c.mu.Unlock()
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return nil, fmt.Errorf("waiting for a connection: %w", context.Cause(ctx))
+		}
+		c.mu.Lock()
+	}
+
+	authMethods, err := c.authMethods()
+	if err != nil {
+		c.mu.Unlock()
+		return nil, err
+	}
+	c.dialing++
+	c.mu.Unlock()
 
 	config := &ssh.ClientConfig{
 		User:            c.username,
 		Auth:            authMethods,
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(), //nolint:gosec // Soft Serve doesn't typically use host key verification
+		HostKeyCallback: c.hostKeyCallback,
+		ClientVersion:   c.version,
+		// Banners and MOTDs are logged rather than surfaced, so they never
+		// end up mixed into command output.
+		BannerCallback: func(message string) error {
+			tflog.Debug(ctx, "Soft Serve SSH banner", map[string]any{"banner": message})
+			return nil
+		},
 	}
+	conn, err := c.dial(ctx, config)
 
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.dialing--
+	if c.dialDone != nil {
+		close(c.dialDone)
+		c.dialDone = nil
+	}
+	if err != nil {
+		return nil, c.rsaKeyRejected(err)
+	}
+	if c.conns == nil {
+		c.conns = make(map[*ssh.Client]int)
+	}
+	c.conns[conn] = 1
+	return conn, nil
+}
+
+// release returns a connection checked out by connect.
+func (c *Client) release(conn *ssh.Client) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if n, ok := c.conns[conn]; ok && n > 0 {
+		c.conns[conn] = n - 1
+	}
+}
+
+// rsaKeyRejected wraps an authentication failure in *RSAKeyRejectedError when
+// every key offered was an RSA key, and returns other errors unchanged. The
+// caller must hold c.mu.
+func (c *Client) rsaKeyRejected(err error) error {
+	if !strings.Contains(err.Error(), "unable to authenticate") {
+		return err
+	}
+	offered := slices.Concat(c.signers, c.agentKeys)
+	if len(offered) == 0 {
+		return err
+	}
+	var fingerprints []string
+	for _, s := range offered {
+		if s.PublicKey().Type() != ssh.KeyAlgoRSA {
+			return err
+		}
+		fingerprints = append(fingerprints, ssh.FingerprintSHA256(s.PublicKey()))
+	}
+	return &RSAKeyRejectedError{Fingerprints: fingerprints, Err: err}
+}
+
+// authMethods returns the auth methods to offer when dialing. Once the agent
+// has returned keys they are reused for later dials; the methods themselves are rebuilt for
+// every dial because oneKeyPerAttempt tracks which key it offered last. The
+// caller must hold c.mu.
+func (c *Client) authMethods() ([]ssh.AuthMethod, error) {
+	var authMethods []ssh.AuthMethod
+	if c.signers != nil {
+		authMethods = append(authMethods, ssh.PublicKeys(c.signers...))
+	}
+	if c.agentSigners != nil {
+		if c.agentKeys == nil {
+			signers, err := c.agentSigners()
+			if err != nil {
+				return nil, fmt.Errorf("listing SSH agent keys: %w", err)
+			}
+			c.agentKeys = signers
+		}
+		if len(c.agentKeys) > 0 {
+			authMethods = append(authMethods, oneKeyPerAttempt(c.agentKeys))
+		}
+	}
+	return authMethods, nil
+}
+
+// disconnect closes conn and drops it from the open connections, so that
+// later commands dial a new one.
+func (c *Client) disconnect(conn *ssh.Client) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.conns, conn)
+	_ = conn.Close()
+}
+
+// dial opens an SSH connection to the server, over the Unix socket when one
+// is configured. Failures to open the TCP or socket connection are retried up
+// to maxRetries times with jittered backoff; the SSH handshake itself is not
+// retried, so authentication errors surface immediately. Retrying stops
+// early once ctx is done or its deadline would pass before the next attempt.
+func (c *Client) dial(ctx context.Context, config *ssh.ClientConfig) (*ssh.Client, error) {
 	addr := fmt.Sprintf("%s:%d", c.host, c.port)
-	conn, err := ssh.Dial("tcp", addr, config)
+	if c.proxyCommand != "" {
+		return c.dialProxyCommand(ctx, addr, config)
+	}
+
+	network, target := "tcp", addr
+	if c.unixSocket != "" {
+		network, target = "unix", c.unixSocket
+	}
+
+	var dialer net.Dialer
+	for attempt := 0; ; attempt++ {
+		nc, err := dialer.DialContext(ctx, network, target)
+		if err == nil {
+			if network == "unix" {
+				// Host key checks expect the remote address to be host:port
+				nc = remoteAddrConn{Conn: nc, addr: proxyAddr(addr)}
+			}
+			conn, err := c.handshake(ctx, nc, addr, config)
+			if err != nil {
+				return nil, fmt.Errorf("connecting to %s: %w", target, err)
+			}
+			return conn, nil
+		}
+
+		if attempt >= c.maxRetries || ctx.Err() != nil {
+			return nil, fmt.Errorf("connecting to %s: %w", target, err)
+		}
+		delay := backoff(attempt, c.retryBaseDelay, c.retryMaxDelay)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return nil, fmt.Errorf("connecting to %s: %w", target, err)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, fmt.Errorf("connecting to %s: %w", target, err)
+		case <-timer.C:
+		}
+	}
+}
+
+// dialProxyCommand opens an SSH connection over the configured proxy
+// command. Starting the command is not retried; when the handshake fails, the
+// command's stderr is included in the error since it usually says why.
+func (c *Client) dialProxyCommand(ctx context.Context, addr string, config *ssh.ClientConfig) (*ssh.Client, error) {
+	pc, err := startProxyCommand(expandProxyCommand(c.proxyCommand, c.host, c.port, c.username), addr)
 	if err != nil {
-		return "", fmt.Errorf("connecting to %s: %w", addr, err)
+		return nil, err
+	}
+	conn, err := c.handshake(ctx, pc, addr, config)
+	if err != nil {
+		if stderr := pc.Stderr(); stderr != "" {
+			return nil, fmt.Errorf("connecting to %s via proxy command: %w (proxy command stderr: %s)", addr, err, stderr)
+		}
+		return nil, fmt.Errorf("connecting to %s via proxy command: %w", addr, err)
 	}
-	defer func() { _ = conn.Close() }()
+	return conn, nil
+}
 
-	session, err := conn.NewSession()
+// handshake runs the SSH handshake over nc, closing nc if it fails. The
+// handshake is abandoned when ctx is done, when the server hasn't identified
+// itself within bannerTimeout, or when it hasn't finished within
+// authTimeout; closing nc is what unblocks it, whatever kind of connection
+// nc is.
+func (c *Client) handshake(ctx context.Context, nc net.Conn, addr string, config *ssh.ClientConfig) (*ssh.Client, error) {
+	if c.authTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, c.authTimeout,
+			fmt.Errorf("SSH handshake did not complete within %s", c.authTimeout))
+		defer cancel()
+	}
+	stop := context.AfterFunc(ctx, func() { _ = nc.Close() })
+
+	bc := &bannerConn{Conn: nc}
+	if c.bannerTimeout > 0 {
+		timer := time.AfterFunc(c.bannerTimeout, func() {
+			if bc.state.CompareAndSwap(bannerPending, bannerTimedOut) {
+				_ = nc.Close()
+			}
+		})
+		defer timer.Stop()
+	}
+
+	sshConn, chans, reqs, err := ssh.NewClientConn(bc, addr, config)
+	if !stop() {
+		// ctx was done, so nc is closed or about to be
+		if err == nil {
+			_ = sshConn.Close()
+		}
+		return nil, context.Cause(ctx)
+	}
 	if err != nil {
-		return "", fmt.Errorf("creating session: %w", err)
+		_ = nc.Close()
+		if bc.state.Load() == bannerTimedOut {
+			return nil, fmt.Errorf("server sent no SSH identification within %s", c.bannerTimeout)
+		}
+		return nil, err
 	}
-	defer func() { _ = session.Close() }()
+	return ssh.NewClient(sshConn, chans, reqs), nil
+}
 
-	var stdout, stderr bytes.Buffer
-	session.Stdout = &stdout
-	session.Stderr = &stderr
+// States of a bannerConn.
+const (
+	bannerPending  int32 = iota // waiting for the server's identification
+	bannerReceived              // identification line read
+	bannerTimedOut              // gave up waiting; the connection is closed
+)
 
-	if err := session.Run(command); err != nil {
-		return "", fmt.Errorf("running command %q: %s: %w", command, strings.TrimSpace(stderr.String()), err)
+// bannerConn watches what the server sends first for the end of its
+// identification line, "SSH-2.0-...". Servers may send other lines before it.
+type bannerConn struct {
+	net.Conn
+	state atomic.Int32
+	line  []byte // start of the line being read, at most 4 bytes
+}
+
+func (b *bannerConn) Read(p []byte) (int, error) {
+	n, err := b.Conn.Read(p)
+	if b.state.Load() != bannerPending {
+		return n, err
+	}
+	for _, ch := range p[:n] {
+		if ch != '\n' {
+			if len(b.line) < 4 {
+				b.line = append(b.line, ch)
+			}
+			continue
+		}
+		if string(b.line) == "SSH-" {
+			b.state.CompareAndSwap(bannerPending, bannerReceived)
+			break
+		}
+		b.line = b.line[:0]
 	}
+	return n, err
+}
 
-	return strings.TrimRight(stdout.String(), "\n"), nil
+// backoff returns the delay before retry attempt n (counting from zero). It
+// uses "full jitter": a uniformly random duration between zero and the
+// exponential backoff for n, capped at maxDelay, so clients that failed at
+// the same moment don't retry in lockstep.
+func backoff(n int, baseDelay, maxDelay time.Duration) time.Duration {
+	ceiling := maxDelay
+	if n < 32 {
+		if d := baseDelay << n; d > 0 && d < maxDelay {
+			ceiling = d
+		}
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return rand.N(ceiling + 1)
 }
 
-// RepoCreate creates a new repository.
-func (c *Client) RepoCreate(name string, opts RepoCreateOpts) error {
-	cmd := fmt.Sprintf("repo create %s", name)
+// Ping checks that the server is reachable and accepts the configured
+// credentials by running the inexpensive `info` command. `info` describes
+// the connected user, so the server refusing it or not finding the user
+// means the key is unregistered, reported as ErrUnregisteredKey.
+func (c *Client) Ping(ctx context.Context) error {
+	_, err := c.Run(ctx, "info")
+	if IsNotFound(err) || IsUnauthorized(err) {
+		return fmt.Errorf("%w: %w", ErrUnregisteredKey, err)
+	}
+	return err
+}
+
+// Info returns the identity the connection authenticates as.
+func (c *Client) Info(ctx context.Context) (*IdentityInfoResult, error) {
+	output, err := c.Run(ctx, "info")
+	if err != nil {
+		return nil, err
+	}
+	return ParseIdentityInfo(output)
+}
+
+// RepoCreate creates a new repository. Visibility is passed as create flags,
+// with private always given an explicit value, so the repository never
+// briefly has the server's default visibility. Servers that reject the flags
+// get a plain create followed by `repo private` and `repo hidden`. Soft Serve
+// doesn't report a version to check, so that is only done for the errors its
+// CLI gives for these flags (see isVisibilityFlagError); any other error,
+// such as one for the name, is returned as it is.
+func (c *Client) RepoCreate(ctx context.Context, name string, opts RepoCreateOpts) error {
+	cmd := fmt.Sprintf("repo create %s", quoteArg(name))
 	if opts.Description != "" {
 		cmd += fmt.Sprintf(" -d %q", opts.Description)
 	}
-	if opts.ProjectName != "" {
-		cmd += fmt.Sprintf(" -n %q", opts.ProjectName)
+	if pn := strings.TrimSpace(opts.ProjectName); pn != "" {
+		cmd += fmt.Sprintf(" -n %q", pn)
 	}
-	if opts.Private {
-		cmd += " -p"
+	if opts.InitialBranch != "" {
+		cmd += fmt.Sprintf(" -b %q", opts.InitialBranch)
 	}
-	_, err := c.Run(cmd)
-	return err
+
+	visibility := fmt.Sprintf(" -p=%t", opts.Private)
+	if opts.Hidden {
+		visibility += " -H"
+	}
+	_, err := c.Run(ctx, cmd+visibility)
+	if err == nil || !isVisibilityFlagError(err) {
+		return err
+	}
+
+	if _, err := c.Run(ctx, cmd); err != nil {
+		return err
+	}
+	if err := c.RepoSetPrivate(ctx, name, opts.Private); err != nil {
+		return err
+	}
+	if opts.Hidden {
+		return c.RepoSetHidden(ctx, name, true)
+	}
+	return nil
 }
 
 // RepoCreateOpts holds options for creating a repository.
 type RepoCreateOpts struct {
+	Description   string
+	ProjectName   string
+	Private       bool
+	Hidden        bool
+	InitialBranch string // Default branch name; empty uses the server default
+}
+
+// visibilityFlagErrors are the errors Soft Serve's CLI gives for the
+// visibility flags of `repo create` when a server doesn't support them: the
+// flag is unknown, or it doesn't take a value.
+var visibilityFlagErrors = []string{
+	"unknown shorthand flag: 'H'",
+	"unknown flag: --hidden",
+	`for "-H, --hidden" flag`,
+	"unknown shorthand flag: 'p'",
+	"unknown flag: --private",
+	`for "-p, --private" flag`,
+}
+
+// isVisibilityFlagError reports whether err is the server rejecting the
+// visibility flags RepoCreate passes. Only the command's stderr is checked,
+// so a name or description quoting one of these errors doesn't match.
+func isVisibilityFlagError(err error) bool {
+	var cmdErr *CommandError
+	if !errors.As(err, &cmdErr) {
+		return false
+	}
+	for _, e := range visibilityFlagErrors {
+		if strings.Contains(cmdErr.Stderr, e) {
+			return true
+		}
+	}
+	return false
+}
+
+// RepoImport creates a repository by importing it from a remote URL, or from
+// a path on the server, which Soft Serve clones like any other remote.
+func (c *Client) RepoImport(ctx context.Context, name, remote string, opts RepoImportOpts) error {
+	if opts.Username != "" {
+		u, err := url.Parse(remote)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("mirror credentials need a URL such as https://host/repo.git, got %q", remote)
+		}
+		if opts.Password != "" {
+			u.User = url.UserPassword(opts.Username, opts.Password)
+		} else {
+			u.User = url.User(opts.Username)
+		}
+		remote = u.String()
+	}
+
+	cmd := fmt.Sprintf("repo import %s %q", quoteArg(name), remote)
+	if opts.Mirror {
+		cmd += " -m"
+	}
+	if opts.Description != "" {
+		cmd += fmt.Sprintf(" -d %q", opts.Description)
+	}
+	if pn := strings.TrimSpace(opts.ProjectName); pn != "" {
+		cmd += fmt.Sprintf(" -n %q", pn)
+	}
+	_, err := c.Run(ctx, cmd)
+	return err
+}
+
+// RepoImportOpts holds options for importing a repository. Visibility is not
+// included because `repo import` doesn't reliably accept the same flags as
+// `repo create`; callers apply it after the import.
+type RepoImportOpts struct {
+	Mirror      bool
 	Description string
 	ProjectName string
-	Private     bool
+
+	// Credentials for the remote, added to its URL. Errors redact them
+	// along with any other credentials in the URL.
+	Username string
+	Password string
 }
 
 // RepoInfo retrieves information about a repository.
-func (c *Client) RepoInfo(name string) (*RepoInfoResult, error) {
-	output, err := c.Run(fmt.Sprintf("repo info %s", name))
+func (c *Client) RepoInfo(ctx context.Context, name string) (*RepoInfoResult, error) {
+	output, err := c.Run(ctx, fmt.Sprintf("repo info %s", quoteArg(name)))
 	if err != nil {
 		return nil, err
 	}
 	return ParseRepoInfo(output)
 }
 
+// RepoList lists the full names of the repositories the user can see.
+func (c *Client) RepoList(ctx context.Context) ([]string, error) {
+	output, err := c.Run(ctx, "repo list")
+	if err != nil {
+		return nil, err
+	}
+	return ParseRepoList(output), nil
+}
+
+// RepoBlob returns the contents of the file at path in a repository, exactly
+// as stored. An empty ref reads from the default branch.
+func (c *Client) RepoBlob(ctx context.Context, name, ref, path string) (string, error) {
+	if ref == "" {
+		ref = "HEAD"
+	}
+	return c.RunRawOutput(ctx, fmt.Sprintf("repo blob %s %s %q", quoteArg(name), quoteArg(ref), path))
+}
+
 // RepoDelete deletes a repository.
-func (c *Client) RepoDelete(name string) error {
-	_, err := c.Run(fmt.Sprintf("repo delete %s", name))
+func (c *Client) RepoDelete(ctx context.Context, name string) error {
+	_, err := c.Run(ctx, fmt.Sprintf("repo delete %s", quoteArg(name)))
 	return err
 }
 
 // RepoSetDescription sets a repository's description.
-func (c *Client) RepoSetDescription(name, description string) error {
-	_, err := c.Run(fmt.Sprintf("repo description %s %q", name, description))
+func (c *Client) RepoSetDescription(ctx context.Context, name, description string) error {
+	_, err := c.Run(ctx, fmt.Sprintf("repo description %s %q", quoteArg(name), description))
 	return err
 }
 
 // RepoSetPrivate sets whether a repository is private.
-func (c *Client) RepoSetPrivate(name string, private bool) error {
-	_, err := c.Run(fmt.Sprintf("repo private %s %t", name, private))
+func (c *Client) RepoSetPrivate(ctx context.Context, name string, private bool) error {
+	_, err := c.Run(ctx, fmt.Sprintf("repo private %s %t", quoteArg(name), private))
 	return err
 }
 
 // RepoSetHidden sets whether a repository is hidden.
-func (c *Client) RepoSetHidden(name string, hidden bool) error {
-	_, err := c.Run(fmt.Sprintf("repo hidden %s %t", name, hidden))
+func (c *Client) RepoSetHidden(ctx context.Context, name string, hidden bool) error {
+	_, err := c.Run(ctx, fmt.Sprintf("repo hidden %s %t", quoteArg(name), hidden))
 	return err
 }
 
-// RepoSetProjectName sets a repository's project name.
-func (c *Client) RepoSetProjectName(name, projectName string) error {
-	_, err := c.Run(fmt.Sprintf("repo project-name %s %q", name, projectName))
+// RepoSetProjectName sets a repository's project name. Surrounding
+// whitespace is trimmed, here and when creating or importing a repository,
+// since `repo info` prints the name trimmed anyway.
+func (c *Client) RepoSetProjectName(ctx context.Context, name, projectName string) error {
+	_, err := c.Run(ctx, fmt.Sprintf("repo project-name %s %q", quoteArg(name), strings.TrimSpace(projectName)))
+	return err
+}
+
+// RepoBranchList lists the branches of a repository.
+func (c *Client) RepoBranchList(ctx context.Context, repo string) ([]string, error) {
+	output, err := c.Run(ctx, fmt.Sprintf("repo branch list %s", quoteArg(repo)))
+	if err != nil {
+		return nil, err
+	}
+	return ParseBranchList(output), nil
+}
+
+// RepoBranchCreate creates a branch in a repository starting at from. An
+// empty from branches off the default branch.
+func (c *Client) RepoBranchCreate(ctx context.Context, repo, branch, from string) error {
+	cmd := fmt.Sprintf("repo branch create %s %s", quoteArg(repo), quoteArg(branch))
+	if from != "" {
+		cmd += " " + quoteArg(from)
+	}
+	_, err := c.Run(ctx, cmd)
+	return err
+}
+
+// RepoBranchDelete deletes a branch from a repository.
+func (c *Client) RepoBranchDelete(ctx context.Context, repo, branch string) error {
+	_, err := c.Run(ctx, fmt.Sprintf("repo branch delete %s %s", quoteArg(repo), quoteArg(branch)))
 	return err
 }
 
 // UserCreate creates a new user.
-func (c *Client) UserCreate(username string, opts UserCreateOpts) error {
-	cmd := fmt.Sprintf("user create %s", username)
+func (c *Client) UserCreate(ctx context.Context, username string, opts UserCreateOpts) error {
+	cmd := fmt.Sprintf("user create %s", quoteArg(username))
 	if opts.Admin {
 		cmd += " -a"
 	}
 	for _, key := range opts.PublicKeys {
 		cmd += fmt.Sprintf(" -k %q", key)
 	}
-	_, err := c.Run(cmd)
+	_, err := c.Run(ctx, cmd)
 	return err
 }
 
@@ -245,51 +1162,98 @@ type UserCreateOpts struct {
 }
 
 // UserInfo retrieves information about a user.
-func (c *Client) UserInfo(username string) (*UserInfoResult, error) {
-	output, err := c.Run(fmt.Sprintf("user info %s", username))
+func (c *Client) UserInfo(ctx context.Context, username string) (*UserInfoResult, error) {
+	output, err := c.Run(ctx, fmt.Sprintf("user info %s", quoteArg(username)))
 	if err != nil {
 		return nil, err
 	}
 	return ParseUserInfo(output)
 }
 
+// UserList lists the usernames of all users.
+func (c *Client) UserList(ctx context.Context) ([]string, error) {
+	output, err := c.Run(ctx, "user list")
+	if err != nil {
+		return nil, err
+	}
+	return ParseUserList(output), nil
+}
+
 // UserDelete deletes a user.
-func (c *Client) UserDelete(username string) error {
-	_, err := c.Run(fmt.Sprintf("user delete %s", username))
+func (c *Client) UserDelete(ctx context.Context, username string) error {
+	_, err := c.Run(ctx, fmt.Sprintf("user delete %s", quoteArg(username)))
 	return err
 }
 
 // UserSetAdmin sets whether a user is an admin.
-func (c *Client) UserSetAdmin(username string, admin bool) error {
-	_, err := c.Run(fmt.Sprintf("user set-admin %s %t", username, admin))
+func (c *Client) UserSetAdmin(ctx context.Context, username string, admin bool) error {
+	_, err := c.Run(ctx, fmt.Sprintf("user set-admin %s %t", quoteArg(username), admin))
 	return err
 }
 
 // UserAddPublicKey adds a public key to a user.
-func (c *Client) UserAddPublicKey(username, key string) error {
-	_, err := c.Run(fmt.Sprintf("user add-pubkey %s %q", username, key))
+func (c *Client) UserAddPublicKey(ctx context.Context, username, key string) error {
+	_, err := c.Run(ctx, fmt.Sprintf("user add-pubkey %s %q", quoteArg(username), key))
 	return err
 }
 
 // UserRemovePublicKey removes a public key from a user.
-func (c *Client) UserRemovePublicKey(username, key string) error {
-	_, err := c.Run(fmt.Sprintf("user remove-pubkey %s %q", username, key))
+func (c *Client) UserRemovePublicKey(ctx context.Context, username, key string) error {
+	_, err := c.Run(ctx, fmt.Sprintf("user remove-pubkey %s %q", quoteArg(username), key))
 	return err
 }
 
 // CollabAdd adds a collaborator to a repository.
-func (c *Client) CollabAdd(repo, username, accessLevel string) error {
-	cmd := fmt.Sprintf("repo collab add %s %s", repo, username)
+func (c *Client) CollabAdd(ctx context.Context, repo, username, accessLevel string) error {
+	cmd := fmt.Sprintf("repo collab add %s %s", quoteArg(repo), quoteArg(username))
 	if accessLevel != "" {
 		cmd += " " + accessLevel
 	}
-	_, err := c.Run(cmd)
+	_, err := c.Run(ctx, cmd)
 	return err
 }
 
+// CollabSetAccess changes the access level of an existing collaborator.
+// Servers that treat `collab add` as an upsert change it in place. There is
+// no version to check, so servers that refuse to re-add an existing
+// collaborator are detected from the error, and the collaborator is removed
+// and added back with the new level instead. If that add fails, the previous
+// level is put back; the error says whether that worked, since otherwise the
+// collaborator is left removed.
+func (c *Client) CollabSetAccess(ctx context.Context, repo, username, accessLevel string) error {
+	err := c.CollabAdd(ctx, repo, username, accessLevel)
+	if err == nil || !IsAlreadyExists(err) {
+		return err
+	}
+
+	collabs, err := c.CollabList(ctx, repo)
+	if err != nil {
+		return err
+	}
+	var previous string
+	for _, collab := range collabs {
+		if collab.Username == username {
+			previous = collab.AccessLevel
+		}
+	}
+
+	if err := c.CollabRemove(ctx, repo, username); err != nil {
+		return err
+	}
+	addErr := c.CollabAdd(ctx, repo, username, accessLevel)
+	if addErr == nil {
+		return nil
+	}
+	if err := c.CollabAdd(ctx, repo, username, previous); err != nil {
+		return fmt.Errorf("collaborator %q was removed from %q to change its access level and could not be added back, so it is no longer a collaborator: %w (restoring it: %v)",
+			username, repo, addErr, err)
+	}
+	return fmt.Errorf("could not change the access level of collaborator %q on %q, so its previous access level was restored: %w", username, repo, addErr)
+}
+
 // CollabList lists collaborators for a repository.
-func (c *Client) CollabList(repo string) ([]CollabEntry, error) {
-	output, err := c.Run(fmt.Sprintf("repo collab list %s", repo))
+func (c *Client) CollabList(ctx context.Context, repo string) ([]CollabEntry, error) {
+	output, err := c.Run(ctx, fmt.Sprintf("repo collab list %s", quoteArg(repo)))
 	if err != nil {
 		return nil, err
 	}
@@ -297,37 +1261,54 @@ func (c *Client) CollabList(repo string) ([]CollabEntry, error) {
 }
 
 // CollabRemove removes a collaborator from a repository.
-func (c *Client) CollabRemove(repo, username string) error {
-	_, err := c.Run(fmt.Sprintf("repo collab remove %s %s", repo, username))
+func (c *Client) CollabRemove(ctx context.Context, repo, username string) error {
+	_, err := c.Run(ctx, fmt.Sprintf("repo collab remove %s %s", quoteArg(repo), quoteArg(username)))
 	return err
 }
 
-// SettingsGetAllowKeyless gets the allow-keyless setting.
-func (c *Client) SettingsGetAllowKeyless() (bool, error) {
-	output, err := c.Run("settings allow-keyless")
+// SettingsGetAllowKeyless gets the allow-keyless setting, as parsed by
+// ParseAllowKeyless.
+func (c *Client) SettingsGetAllowKeyless(ctx context.Context) (bool, error) {
+	output, err := c.SettingsGet(ctx, "allow-keyless")
 	if err != nil {
 		return false, err
 	}
-	return strings.TrimSpace(output) == "true", nil
+	return ParseAllowKeyless(output)
 }
 
 // SettingsSetAllowKeyless sets the allow-keyless setting.
-func (c *Client) SettingsSetAllowKeyless(allow bool) error {
-	_, err := c.Run(fmt.Sprintf("settings allow-keyless %t", allow))
-	return err
+func (c *Client) SettingsSetAllowKeyless(ctx context.Context, allow bool) error {
+	return c.SettingsSet(ctx, "allow-keyless", strconv.FormatBool(allow))
 }
 
-// SettingsGetAnonAccess gets the anonymous access level.
-func (c *Client) SettingsGetAnonAccess() (string, error) {
-	output, err := c.Run("settings anon-access")
+// SettingsGetAnonAccess gets the anonymous access level, as parsed by
+// ParseAnonAccess.
+func (c *Client) SettingsGetAnonAccess(ctx context.Context) (string, error) {
+	output, err := c.SettingsGet(ctx, "anon-access")
 	if err != nil {
 		return "", err
 	}
-	return strings.TrimSpace(output), nil
+	return ParseAnonAccess(output), nil
 }
 
 // SettingsSetAnonAccess sets the anonymous access level.
-func (c *Client) SettingsSetAnonAccess(level string) error {
-	_, err := c.Run(fmt.Sprintf("settings anon-access %s", level))
+func (c *Client) SettingsSetAnonAccess(ctx context.Context, level string) error {
+	return c.SettingsSet(ctx, "anon-access", level)
+}
+
+// SettingsGet returns the value of any server setting as printed by
+// `settings <key>`, trimmed but otherwise unparsed, for settings without a
+// typed getter.
+func (c *Client) SettingsGet(ctx context.Context, key string) (string, error) {
+	output, err := c.Run(ctx, "settings "+quoteArg(key))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// SettingsSet sets any server setting with `settings <key> <value>`.
+func (c *Client) SettingsSet(ctx context.Context, key, value string) error {
+	_, err := c.Run(ctx, fmt.Sprintf("settings %s %s", quoteArg(key), quoteArg(value)))
 	return err
 }
@@ -0,0 +1,467 @@
+package ssh
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/format/packfile"
+	"github.com/go-git/go-git/v5/plumbing/format/pktline"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/protocol/packp"
+	"github.com/go-git/go-git/v5/plumbing/protocol/packp/capability"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"golang.org/x/crypto/ssh"
+)
+
+// ErrBranchMoved is returned by RepoPutFile and RepoDeleteFile when the
+// branch gains a commit between fetching it and pushing to it.
+var ErrBranchMoved = errors.New("branch changed while committing; try again")
+
+// ErrBranchNotFound is returned by RepoPutFile and RepoDeleteFile when the
+// branch doesn't exist in a repository that already has commits.
+var ErrBranchNotFound = errors.New("branch not found")
+
+// CommitOpts holds the message and author of a commit made by RepoPutFile or
+// RepoDeleteFile. The author is also recorded as the committer.
+type CommitOpts struct {
+	Message     string
+	AuthorName  string
+	AuthorEmail string
+}
+
+// RepoPutFile commits content to the file at path on branch and returns the
+// hash of the branch's new head. Soft Serve has no command for writing files,
+// so the branch is fetched and the commit pushed with git's own protocol over
+// the shared connection, without the command prefix or subsystem. When the
+// file already holds content, no commit is made and the current head is
+// returned. A branch that doesn't exist is only created in a repository with
+// no commits yet.
+func (c *Client) RepoPutFile(ctx context.Context, repo, branch, path, content string, opts CommitOpts) (string, error) {
+	return c.commitFile(ctx, repo, branch, path, &content, opts)
+}
+
+// RepoDeleteFile commits the removal of the file at path from branch, along
+// with any directories left empty, and returns the hash of the branch's new
+// head. When the file doesn't exist, no commit is made and the current head,
+// or "" for a missing branch, is returned.
+func (c *Client) RepoDeleteFile(ctx context.Context, repo, branch, path string, opts CommitOpts) (string, error) {
+	return c.commitFile(ctx, repo, branch, path, nil, opts)
+}
+
+// commitFile commits content to path on branch, or removes path when content
+// is nil.
+func (c *Client) commitFile(ctx context.Context, repo, branch, path string, content *string, opts CommitOpts) (string, error) {
+	parts, err := splitFilePath(path)
+	if err != nil {
+		return "", err
+	}
+	ref := plumbing.NewBranchReferenceName(branch)
+	if err := ref.Validate(); err != nil {
+		return "", fmt.Errorf("invalid branch %q: %w", branch, err)
+	}
+
+	store := memory.NewStorage()
+	head, err := c.fetchBranch(ctx, repo, ref, store)
+	if err != nil {
+		return "", err
+	}
+	if head.IsZero() && content == nil {
+		return "", nil
+	}
+
+	w := &objectWriter{store: store}
+	var tree plumbing.Hash
+	if !head.IsZero() {
+		commit, err := object.GetCommit(store, head)
+		if err != nil {
+			return "", fmt.Errorf("reading commit %s: %w", head, err)
+		}
+		tree = commit.TreeHash
+	}
+
+	var blob plumbing.Hash
+	if content != nil {
+		if blob, err = w.writeBlob([]byte(*content)); err != nil {
+			return "", err
+		}
+	}
+	newTree, err := w.putPath(tree, parts, blob)
+	if err != nil {
+		return "", fmt.Errorf("updating %s: %w", path, err)
+	}
+	if newTree == tree {
+		return head.String(), nil
+	}
+	if newTree.IsZero() {
+		// Every file is gone; a commit still needs a tree.
+		if newTree, err = w.writeTree(nil); err != nil {
+			return "", err
+		}
+	}
+
+	message := opts.Message
+	if !strings.HasSuffix(message, "\n") {
+		message += "\n"
+	}
+	sig := object.Signature{Name: opts.AuthorName, Email: opts.AuthorEmail, When: time.Now()}
+	commit := &object.Commit{Author: sig, Committer: sig, Message: message, TreeHash: newTree}
+	if !head.IsZero() {
+		commit.ParentHashes = []plumbing.Hash{head}
+	}
+	hash, err := w.write(commit)
+	if err != nil {
+		return "", err
+	}
+
+	if err := c.pushBranch(ctx, repo, ref, head, hash, store, w.written); err != nil {
+		return "", err
+	}
+	return hash.String(), nil
+}
+
+// splitFilePath splits a file path within a repository into its names,
+// rejecting paths git can't store.
+func splitFilePath(path string) ([]string, error) {
+	parts := strings.Split(path, "/")
+	for _, part := range parts {
+		if part == "" || part == "." || part == ".." || part == ".git" {
+			return nil, fmt.Errorf("invalid file path %q: must be relative, without empty, \".\", \"..\" or \".git\" elements", path)
+		}
+	}
+	return parts, nil
+}
+
+// fetchBranch fetches the head commit of ref, and its trees, into store and
+// returns its hash. It returns the zero hash when ref doesn't exist in a
+// repository without commits, and ErrBranchNotFound when it doesn't exist in
+// one with commits.
+func (c *Client) fetchBranch(ctx context.Context, repo string, ref plumbing.ReferenceName, store *memory.Storage) (plumbing.Hash, error) {
+	s, err := c.startGit(ctx, "git-upload-pack", repo)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	defer s.close()
+
+	adv, err := s.advertisedRefs()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	head, ok := adv.References[ref.String()]
+	if !ok {
+		// A flush ends the conversation without fetching anything.
+		_, _ = s.stdin.Write(pktline.FlushPkt)
+		if err := s.wait(); err != nil {
+			return plumbing.ZeroHash, err
+		}
+		if len(adv.References) > 0 {
+			return plumbing.ZeroHash, fmt.Errorf("%w: %q in repository %q", ErrBranchNotFound, ref.Short(), repo)
+		}
+		return plumbing.ZeroHash, nil
+	}
+
+	// Only the head commit's trees are needed, so history and, where the
+	// server allows it, file contents are left out.
+	req := packp.NewUploadPackRequest()
+	req.Wants = []plumbing.Hash{head}
+	if adv.Capabilities.Supports(capability.OFSDelta) {
+		_ = req.Capabilities.Set(capability.OFSDelta)
+	}
+	if adv.Capabilities.Supports(capability.Shallow) {
+		_ = req.Capabilities.Set(capability.Shallow)
+		req.Depth = packp.DepthCommits(1)
+	}
+	if adv.Capabilities.Supports(capability.Filter) {
+		_ = req.Capabilities.Set(capability.Filter)
+		req.Filter = packp.FilterBlobNone()
+	}
+	if err := req.UploadRequest.Encode(s.stdin); err != nil {
+		return plumbing.ZeroHash, s.fail(fmt.Errorf("sending fetch request: %w", err))
+	}
+	if err := req.UploadHaves.Encode(s.stdin, true); err != nil {
+		return plumbing.ZeroHash, s.fail(fmt.Errorf("sending fetch request: %w", err))
+	}
+	if err := pktline.NewEncoder(s.stdin).Encodef("done\n"); err != nil {
+		return plumbing.ZeroHash, s.fail(fmt.Errorf("sending fetch request: %w", err))
+	}
+	_ = s.stdin.Close()
+
+	resp := packp.NewUploadPackResponse(req)
+	if err := resp.Decode(io.NopCloser(s.stdout)); err != nil {
+		return plumbing.ZeroHash, s.fail(fmt.Errorf("reading fetch response: %w", err))
+	}
+	if err := packfile.UpdateObjectStorage(store, resp); err != nil {
+		return plumbing.ZeroHash, s.fail(fmt.Errorf("reading fetched objects: %w", err))
+	}
+	return head, s.wait()
+}
+
+// pushBranch sends objects, which must include commit and every object it
+// adds, and moves ref from old to commit. It fails with ErrBranchMoved when
+// ref is no longer at old.
+func (c *Client) pushBranch(ctx context.Context, repo string, ref plumbing.ReferenceName, old, commit plumbing.Hash, store *memory.Storage, objects []plumbing.Hash) error {
+	s, err := c.startGit(ctx, "git-receive-pack", repo)
+	if err != nil {
+		return err
+	}
+	defer s.close()
+
+	adv, err := s.advertisedRefs()
+	if err != nil {
+		return err
+	}
+	if current := adv.References[ref.String()]; current != old {
+		_, _ = s.stdin.Write(pktline.FlushPkt)
+		_ = s.wait()
+		return fmt.Errorf("%w: %q in repository %q is now at %s", ErrBranchMoved, ref.Short(), repo, current)
+	}
+
+	var pack bytes.Buffer
+	if _, err := packfile.NewEncoder(&pack, store, false).Encode(objects, 10); err != nil {
+		return s.fail(fmt.Errorf("packing objects: %w", err))
+	}
+
+	req := packp.NewReferenceUpdateRequestFromCapabilities(adv.Capabilities)
+	req.Commands = []*packp.Command{{Name: ref, Old: old, New: commit}}
+	req.Packfile = io.NopCloser(&pack)
+	if err := req.Encode(s.stdin); err != nil {
+		return s.fail(fmt.Errorf("sending push request: %w", err))
+	}
+	_ = s.stdin.Close()
+
+	if req.Capabilities.Supports(capability.ReportStatus) {
+		report := packp.NewReportStatus()
+		if err := report.Decode(s.stdout); err != nil {
+			return s.fail(fmt.Errorf("reading push status: %w", err))
+		}
+		if err := report.Error(); err != nil {
+			return s.fail(err)
+		}
+	}
+	return s.wait()
+}
+
+// objectWriter stores new git objects and records their hashes, so that
+// exactly those are pushed.
+type objectWriter struct {
+	store   *memory.Storage
+	written []plumbing.Hash
+}
+
+type encodable interface {
+	Encode(plumbing.EncodedObject) error
+}
+
+func (w *objectWriter) write(o encodable) (plumbing.Hash, error) {
+	obj := w.store.NewEncodedObject()
+	if err := o.Encode(obj); err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("encoding object: %w", err)
+	}
+	return w.set(obj)
+}
+
+func (w *objectWriter) writeBlob(content []byte) (plumbing.Hash, error) {
+	obj := w.store.NewEncodedObject()
+	obj.SetType(plumbing.BlobObject)
+	ow, err := obj.Writer()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	if _, err := ow.Write(content); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	if err := ow.Close(); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return w.set(obj)
+}
+
+// writeTree stores a tree of entries, sorting them as git requires:
+// by name, with directory names compared as if they ended in "/".
+func (w *objectWriter) writeTree(entries []object.TreeEntry) (plumbing.Hash, error) {
+	sortKey := func(e object.TreeEntry) string {
+		if e.Mode == filemode.Dir {
+			return e.Name + "/"
+		}
+		return e.Name
+	}
+	slices.SortFunc(entries, func(a, b object.TreeEntry) int {
+		return strings.Compare(sortKey(a), sortKey(b))
+	})
+	return w.write(&object.Tree{Entries: entries})
+}
+
+func (w *objectWriter) set(obj plumbing.EncodedObject) (plumbing.Hash, error) {
+	hash, err := w.store.SetEncodedObject(obj)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("storing object: %w", err)
+	}
+	if !slices.Contains(w.written, hash) {
+		w.written = append(w.written, hash)
+	}
+	return hash, nil
+}
+
+// putPath returns the hash of a tree like the one at tree, which is zero for
+// an empty tree, with the file at parts set to blob, or removed when blob is
+// zero. A zero hash is returned when the tree ends up empty, and tree itself
+// when nothing changed. An executable file keeps its mode.
+func (w *objectWriter) putPath(tree plumbing.Hash, parts []string, blob plumbing.Hash) (plumbing.Hash, error) {
+	var entries []object.TreeEntry
+	if !tree.IsZero() {
+		t, err := object.GetTree(w.store, tree)
+		if err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("reading tree %s: %w", tree, err)
+		}
+		entries = slices.Clone(t.Entries)
+	}
+
+	name := parts[0]
+	i := slices.IndexFunc(entries, func(e object.TreeEntry) bool { return e.Name == name })
+
+	var hash plumbing.Hash
+	var mode filemode.FileMode
+	if len(parts) == 1 {
+		if i >= 0 && entries[i].Mode == filemode.Dir {
+			return plumbing.ZeroHash, fmt.Errorf("%s is a directory", name)
+		}
+		hash, mode = blob, filemode.Regular
+		if i >= 0 && entries[i].Mode == filemode.Executable {
+			mode = filemode.Executable
+		}
+	} else {
+		var sub plumbing.Hash
+		if i >= 0 {
+			if entries[i].Mode != filemode.Dir {
+				return plumbing.ZeroHash, fmt.Errorf("%s is not a directory", name)
+			}
+			sub = entries[i].Hash
+		}
+		var err error
+		if hash, err = w.putPath(sub, parts[1:], blob); err != nil {
+			return plumbing.ZeroHash, err
+		}
+		mode = filemode.Dir
+	}
+
+	switch {
+	case i < 0 && hash.IsZero():
+		return tree, nil
+	case i >= 0 && entries[i].Hash == hash && entries[i].Mode == mode:
+		return tree, nil
+	case i >= 0:
+		entries = slices.Delete(entries, i, i+1)
+	}
+	if !hash.IsZero() {
+		entries = append(entries, object.TreeEntry{Name: name, Mode: mode, Hash: hash})
+	}
+	if len(entries) == 0 {
+		return plumbing.ZeroHash, nil
+	}
+	return w.writeTree(entries)
+}
+
+// gitSession is a git service such as git-receive-pack running on the
+// server, which the client converses with over stdin and stdout.
+type gitSession struct {
+	command string
+	session *ssh.Session
+	stdin   io.WriteCloser
+	stdout  io.Reader
+	stderr  cappedBuffer
+	verbose bool
+	stop    func() bool // stops closing the session when ctx is done
+	release func()      // returns the connection to the client
+}
+
+// startGit starts service, e.g. git-upload-pack, for repository repo, as a
+// git client would for the repository's SSH clone URL. A connection closed
+// under us is redialed once, as in RunRawOutput.
+func (c *Client) startGit(ctx context.Context, service, repo string) (*gitSession, error) {
+	if c.limiter != nil {
+		if err := c.limiter.Wait(ctx); err != nil {
+			return nil, fmt.Errorf("waiting to send command: %w", err)
+		}
+	}
+	command := fmt.Sprintf("%s %s", service, quoteArg("/"+repo))
+
+	for redialed := false; ; redialed = true {
+		conn, err := c.connect(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		session, err := conn.NewSession()
+		if err != nil {
+			c.disconnect(conn)
+			if redialed || !connectionLost(err) {
+				return nil, fmt.Errorf("creating session: %w", err)
+			}
+			tflog.Debug(ctx, "Soft Serve SSH connection lost, redialing", map[string]any{"error": err.Error()})
+			continue
+		}
+		sendEnv(ctx, c.env, session.Setenv)
+
+		s := &gitSession{command: command, session: session, verbose: c.verbose}
+		session.Stderr = &s.stderr
+		if s.stdin, err = session.StdinPipe(); err == nil {
+			s.stdout, err = session.StdoutPipe()
+		}
+		if err == nil {
+			err = session.Start(command)
+		}
+		if err != nil {
+			_ = session.Close()
+			c.release(conn)
+			return nil, &CommandError{Command: command, Err: err, Verbose: c.verbose}
+		}
+		s.stop = context.AfterFunc(ctx, func() { _ = session.Close() })
+		s.release = func() { c.release(conn) }
+		return s, nil
+	}
+}
+
+// advertisedRefs reads the references and capabilities the service starts
+// by sending. A repository without commits may advertise nothing at all.
+func (s *gitSession) advertisedRefs() (*packp.AdvRefs, error) {
+	adv := packp.NewAdvRefs()
+	if err := adv.Decode(s.stdout); err != nil && !errors.Is(err, packp.ErrEmptyAdvRefs) {
+		return nil, s.fail(fmt.Errorf("reading advertised references: %w", err))
+	}
+	return adv, nil
+}
+
+// wait waits for the service to exit, returning a *CommandError if it
+// failed. Unread output is discarded so the service isn't left blocked
+// writing it.
+func (s *gitSession) wait() error {
+	_ = s.stdin.Close()
+	_, _ = io.Copy(io.Discard, s.stdout)
+	if err := s.session.Wait(); err != nil {
+		return &CommandError{Command: s.command, Stderr: s.stderr.String(), Err: err, Verbose: s.verbose}
+	}
+	return nil
+}
+
+// fail returns err for a failed conversation, preferring the service's own
+// error, such as a missing repository, when it exited unsuccessfully.
+func (s *gitSession) fail(err error) error {
+	if waitErr := s.wait(); waitErr != nil {
+		return waitErr
+	}
+	return err
+}
+
+func (s *gitSession) close() {
+	s.stop()
+	_ = s.session.Close()
+	s.release()
+}
@@ -0,0 +1,301 @@
+package ssh
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ssoriche/terraform-provider-soft-serve/internal/sshtest"
+)
+
+// newGitTestClient returns a client for a test server that serves the bare
+// repositories in the returned directory with the git binary.
+func newGitTestClient(t *testing.T) (*Client, *sshtest.Server, string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	dir := t.TempDir()
+	c, srv := newTestClient(t, func(string) (string, error) { return "", nil })
+	for _, service := range []string{"upload-pack", "receive-pack"} {
+		srv.HandleStream("git-"+service+" ", func(command string, stdin io.Reader, stdout, stderr io.Writer) error {
+			// Undo quoteArg, which leaves safe names bare
+			_, arg, _ := strings.Cut(command, " ")
+			name := strings.Trim(strings.ReplaceAll(arg, `'\''`, "'"), "'/")
+			return serveGit(service, filepath.Join(dir, name+".git"), stdin, stdout, stderr)
+		})
+	}
+	return c, srv, dir
+}
+
+// serveGit runs git service, e.g. upload-pack, for the repository at path.
+// Stdin is copied without waiting for it to end, since a service that fails
+// early, say for a missing repository, exits before the client is done.
+func serveGit(service, path string, stdin io.Reader, stdout, stderr io.Writer) error {
+	cmd := exec.Command("git", service, path)
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	go func() {
+		_, _ = io.Copy(in, stdin)
+		_ = in.Close()
+	}()
+	return cmd.Run()
+}
+
+// git runs git with args and returns its trimmed output.
+func git(t *testing.T, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-c", "user.name=Seed", "-c", "user.email=seed@example.com"}, args...)...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// initBareRepo creates repository name in dir with a main branch holding
+// files, or no commits when files is empty, and returns its path.
+func initBareRepo(t *testing.T, dir, name string, files map[string]string) string {
+	t.Helper()
+	bare := filepath.Join(dir, name+".git")
+	git(t, "init", "-q", "--bare", "-b", "main", bare)
+	git(t, "-C", bare, "config", "uploadpack.allowFilter", "true")
+	if len(files) > 0 {
+		commitFiles(t, bare, files)
+	}
+	return bare
+}
+
+// commitFiles pushes a commit writing files to the main branch of bare.
+func commitFiles(t *testing.T, bare string, files map[string]string) {
+	t.Helper()
+	work := filepath.Join(t.TempDir(), "work")
+	git(t, "clone", "-q", bare, work)
+	git(t, "-C", work, "checkout", "-q", "-B", "main")
+	for path, content := range files {
+		full := filepath.Join(work, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git(t, "-C", work, "add", "-A")
+	git(t, "-C", work, "commit", "-q", "-m", "seed")
+	git(t, "-C", work, "push", "-q", bare, "main")
+}
+
+var testCommit = CommitOpts{Message: "Add guide", AuthorName: "Terraform", AuthorEmail: "terraform@example.com"}
+
+func TestRepoPutFile_NewFile(t *testing.T) {
+	c, srv, dir := newGitTestClient(t)
+	bare := initBareRepo(t, dir, "myrepo", map[string]string{"README.md": "# myrepo\n", "docs/intro.md": "intro\n"})
+	parent := git(t, "-C", bare, "rev-parse", "main")
+
+	hash, err := c.RepoPutFile(context.Background(), "myrepo", "main", "docs/guide.md", "Read me.\n", testCommit)
+	if err != nil {
+		t.Fatalf("RepoPutFile() error = %v", err)
+	}
+
+	if head := git(t, "-C", bare, "rev-parse", "main"); hash != head {
+		t.Errorf("RepoPutFile() = %s, want new head %s", hash, head)
+	}
+	if got := git(t, "-C", bare, "show", "main:docs/guide.md"); got != "Read me." {
+		t.Errorf("docs/guide.md = %q, want %q", got, "Read me.")
+	}
+	if got := git(t, "-C", bare, "ls-tree", "-r", "--name-only", "main"); got != "README.md\ndocs/guide.md\ndocs/intro.md" {
+		t.Errorf("files = %q, want README.md, docs/guide.md and docs/intro.md", got)
+	}
+	want := "Terraform <terraform@example.com>|Terraform <terraform@example.com>|Add guide|" + parent
+	if got := git(t, "-C", bare, "log", "-1", "--format=%an <%ae>|%cn <%ce>|%s|%P", "main"); got != want {
+		t.Errorf("commit = %q, want %q", got, want)
+	}
+	git(t, "-C", bare, "fsck", "--strict")
+
+	if got, want := srv.Commands(), []string{"git-upload-pack /myrepo", "git-receive-pack /myrepo"}; !slices.Equal(got, want) {
+		t.Errorf("commands = %q, want %q", got, want)
+	}
+}
+
+func TestRepoPutFile_Unchanged(t *testing.T) {
+	c, srv, dir := newGitTestClient(t)
+	bare := initBareRepo(t, dir, "myrepo", map[string]string{"README.md": "# myrepo\n"})
+	head := git(t, "-C", bare, "rev-parse", "main")
+
+	hash, err := c.RepoPutFile(context.Background(), "myrepo", "main", "README.md", "# myrepo\n", testCommit)
+	if err != nil {
+		t.Fatalf("RepoPutFile() error = %v", err)
+	}
+	if hash != head {
+		t.Errorf("RepoPutFile() = %s, want unchanged head %s", hash, head)
+	}
+	if got, want := srv.Commands(), []string{"git-upload-pack /myrepo"}; !slices.Equal(got, want) {
+		t.Errorf("commands = %q, want %q", got, want)
+	}
+}
+
+func TestRepoPutFile_Overwrite(t *testing.T) {
+	c, _, dir := newGitTestClient(t)
+	bare := initBareRepo(t, dir, "myrepo", map[string]string{"README.md": "old\n"})
+
+	if _, err := c.RepoPutFile(context.Background(), "myrepo", "main", "README.md", "new\n", testCommit); err != nil {
+		t.Fatalf("RepoPutFile() error = %v", err)
+	}
+	if got := git(t, "-C", bare, "show", "main:README.md"); got != "new" {
+		t.Errorf("README.md = %q, want %q", got, "new")
+	}
+	if got := git(t, "-C", bare, "rev-list", "--count", "main"); got != "2" {
+		t.Errorf("commits = %s, want 2", got)
+	}
+}
+
+func TestRepoPutFile_EmptyRepository(t *testing.T) {
+	c, _, dir := newGitTestClient(t)
+	bare := initBareRepo(t, dir, "myrepo", nil)
+
+	hash, err := c.RepoPutFile(context.Background(), "myrepo", "main", "README.md", "# myrepo\n", testCommit)
+	if err != nil {
+		t.Fatalf("RepoPutFile() error = %v", err)
+	}
+	if got := git(t, "-C", bare, "log", "--format=%H|%P", "main"); got != hash+"|" {
+		t.Errorf("history = %q, want a single root commit %s", got, hash)
+	}
+	if got := git(t, "-C", bare, "show", "main:README.md"); got != "# myrepo" {
+		t.Errorf("README.md = %q, want %q", got, "# myrepo")
+	}
+}
+
+func TestRepoPutFile_MissingBranch(t *testing.T) {
+	c, srv, dir := newGitTestClient(t)
+	initBareRepo(t, dir, "myrepo", map[string]string{"README.md": "# myrepo\n"})
+
+	_, err := c.RepoPutFile(context.Background(), "myrepo", "develop", "README.md", "hi\n", testCommit)
+	if !errors.Is(err, ErrBranchNotFound) {
+		t.Fatalf("RepoPutFile() error = %v, want ErrBranchNotFound", err)
+	}
+	if got, want := srv.Commands(), []string{"git-upload-pack /myrepo"}; !slices.Equal(got, want) {
+		t.Errorf("commands = %q, want %q", got, want)
+	}
+}
+
+func TestRepoPutFile_BranchMoved(t *testing.T) {
+	c, srv, dir := newGitTestClient(t)
+	bare := initBareRepo(t, dir, "myrepo", map[string]string{"README.md": "# myrepo\n"})
+
+	// Someone else pushes between the fetch and the push.
+	head := git(t, "-C", bare, "rev-parse", "main")
+	commitFiles(t, bare, map[string]string{"OTHER.md": "other\n"})
+	git(t, "-C", bare, "branch", "-f", "other", "main")
+	git(t, "-C", bare, "update-ref", "refs/heads/main", head)
+	srv.HandleStream("git-receive-pack ", func(_ string, stdin io.Reader, stdout, stderr io.Writer) error {
+		if err := exec.Command("git", "-C", bare, "update-ref", "refs/heads/main", "other").Run(); err != nil {
+			return err
+		}
+		return serveGit("receive-pack", bare, stdin, stdout, stderr)
+	})
+
+	_, err := c.RepoPutFile(context.Background(), "myrepo", "main", "docs/guide.md", "Read me.\n", testCommit)
+	if !errors.Is(err, ErrBranchMoved) {
+		t.Fatalf("RepoPutFile() error = %v, want ErrBranchMoved", err)
+	}
+	if got := git(t, "-C", bare, "ls-tree", "-r", "--name-only", "main"); got != "OTHER.md\nREADME.md" {
+		t.Errorf("files = %q, want the other push's", got)
+	}
+}
+
+func TestRepoPutFile_InvalidPath(t *testing.T) {
+	c, srv, dir := newGitTestClient(t)
+	initBareRepo(t, dir, "myrepo", map[string]string{"README.md": "# myrepo\n"})
+
+	for _, path := range []string{"", "/etc/passwd", "docs//guide.md", "docs/", "../secret", "docs/./guide.md", ".git/config"} {
+		if _, err := c.RepoPutFile(context.Background(), "myrepo", "main", path, "x", testCommit); err == nil {
+			t.Errorf("RepoPutFile(%q) should fail", path)
+		}
+	}
+	if cmds := srv.Commands(); len(cmds) != 0 {
+		t.Errorf("invalid paths should be rejected before connecting, sent %q", cmds)
+	}
+
+	_, err := c.RepoPutFile(context.Background(), "myrepo", "main", "README.md/guide.md", "x", testCommit)
+	if err == nil || !strings.Contains(err.Error(), "README.md is not a directory") {
+		t.Errorf("RepoPutFile() through a file error = %v, want not a directory", err)
+	}
+	_, err = c.RepoPutFile(context.Background(), "myrepo", "main", "README.md", "x", testCommit)
+	if err != nil {
+		t.Fatalf("RepoPutFile() error = %v", err)
+	}
+}
+
+func TestRepoDeleteFile(t *testing.T) {
+	c, _, dir := newGitTestClient(t)
+	bare := initBareRepo(t, dir, "myrepo", map[string]string{"README.md": "# myrepo\n", "docs/guide.md": "guide\n"})
+
+	hash, err := c.RepoDeleteFile(context.Background(), "myrepo", "main", "docs/guide.md", CommitOpts{Message: "Remove guide"})
+	if err != nil {
+		t.Fatalf("RepoDeleteFile() error = %v", err)
+	}
+	if head := git(t, "-C", bare, "rev-parse", "main"); hash != head {
+		t.Errorf("RepoDeleteFile() = %s, want new head %s", hash, head)
+	}
+	// The emptied docs directory goes too.
+	if got := git(t, "-C", bare, "ls-tree", "-r", "-t", "--name-only", "main"); got != "README.md" {
+		t.Errorf("files = %q, want only README.md", got)
+	}
+
+	again, err := c.RepoDeleteFile(context.Background(), "myrepo", "main", "docs/guide.md", CommitOpts{Message: "Remove guide"})
+	if err != nil {
+		t.Fatalf("RepoDeleteFile() of a missing file error = %v", err)
+	}
+	if again != hash {
+		t.Errorf("RepoDeleteFile() of a missing file = %s, want unchanged head %s", again, hash)
+	}
+}
+
+func TestRepoDeleteFile_LastFile(t *testing.T) {
+	c, _, dir := newGitTestClient(t)
+	bare := initBareRepo(t, dir, "myrepo", map[string]string{"README.md": "# myrepo\n"})
+
+	if _, err := c.RepoDeleteFile(context.Background(), "myrepo", "main", "README.md", CommitOpts{Message: "Empty"}); err != nil {
+		t.Fatalf("RepoDeleteFile() error = %v", err)
+	}
+	if got := git(t, "-C", bare, "ls-tree", "main"); got != "" {
+		t.Errorf("tree = %q, want empty", got)
+	}
+}
+
+func TestRepoPutFile_QuotesRepositoryName(t *testing.T) {
+	c, srv, dir := newGitTestClient(t)
+	bare := initBareRepo(t, dir, "team's-repo", map[string]string{"README.md": "# repo\n"})
+
+	if _, err := c.RepoPutFile(context.Background(), "team's-repo", "main", "NOTES.md", "Notes.", testCommit); err != nil {
+		t.Fatalf("RepoPutFile() error = %v", err)
+	}
+	if got := git(t, "-C", bare, "show", "main:NOTES.md"); got != "Notes." {
+		t.Errorf("NOTES.md = %q, want %q", got, "Notes.")
+	}
+	want := []string{`git-upload-pack '/team'\''s-repo'`, `git-receive-pack '/team'\''s-repo'`}
+	if got := srv.Commands(); !slices.Equal(got, want) {
+		t.Errorf("commands = %q, want %q", got, want)
+	}
+}
+
+func TestRepoPutFile_RepositoryNotFound(t *testing.T) {
+	c, _, _ := newGitTestClient(t)
+
+	_, err := c.RepoPutFile(context.Background(), "missing", "main", "README.md", "x", testCommit)
+	var cmdErr *CommandError
+	if !errors.As(err, &cmdErr) || cmdErr.Command != "git-upload-pack /missing" {
+		t.Fatalf("RepoPutFile() error = %v, want a *CommandError for git-upload-pack", err)
+	}
+}
@@ -1,25 +1,55 @@
 package ssh
 
 import (
+	"cmp"
 	"fmt"
+	"math"
+	"net/url"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
 )
 
 // RepoInfoResult holds parsed repository information.
 type RepoInfoResult struct {
-	ProjectName string
-	Repository  string
-	Description string
-	Private     bool
-	Hidden      bool
-	Mirror      bool
-	Owner       string
+	ProjectName   string
+	Repository    string
+	Description   string
+	Private       bool
+	HasPrivate    bool // Private was reported; when false, Private is meaningless
+	Hidden        bool
+	Mirror        bool
+	MirrorURL     string // Upstream of a mirror, without credentials; empty otherwise
+	Owner         string
+	Access        string // Access level of the connected user; empty on older servers
+	DefaultBranch string
+	Branches      []string
+	Tags          []string
+	CreatedAt     string // RFC3339; empty when the server doesn't report it
+	UpdatedAt     string // RFC3339; empty when the server doesn't report it
+	LastPush      string // RFC3339; empty when the server doesn't report it
+	Size          int64  // Disk size in bytes
+	HasSize       bool   // Size was reported; when false, Size is meaningless
 }
 
 // UserInfoResult holds parsed user information.
 type UserInfoResult struct {
+	Username          string
+	Admin             bool
+	PublicKeys        []string // "type base64", without any comment
+	PublicKeyComments []string // Comment of each of PublicKeys; empty for keys printed without one
+	CreatedAt         string   // RFC3339; empty when the server doesn't report it
+	UpdatedAt         string   // RFC3339; empty when the server doesn't report it
+}
+
+// IdentityInfoResult holds the parsed identity of the connected user.
+type IdentityInfoResult struct {
 	Username   string
 	Admin      bool
+	Access     string // Reported access level; empty when the server omits it
 	PublicKeys []string
 }
 
@@ -29,7 +59,8 @@ type CollabEntry struct {
 	AccessLevel string
 }
 
-// ParseRepoInfo parses the output of `repo info <name>`.
+// ParseRepoInfo parses the output of `repo info <name>`. Labels are matched
+// case-insensitively and a few alternative spellings are accepted.
 //
 // Expected format:
 //
@@ -40,41 +71,68 @@ type CollabEntry struct {
 //	Hidden: false
 //	Mirror: false
 //	Owner: admin
+//	Access: admin-access
 //	Default Branch: main
 //	Branches:
 //	  - main
 //	Tags:
+//
+// Mirrors also print their upstream, e.g. "Mirror URL: https://...". Newer
+// servers may also print "Created At" and "Updated At" timestamps, and
+// servers that track activity a "Last Push" one, which are converted to
+// RFC3339. A reported disk size, e.g. "Size: 1.5 MiB" or "Size: 2048", is
+// converted to bytes.
 func ParseRepoInfo(output string) (*RepoInfoResult, error) {
 	result := &RepoInfoResult{}
 	kvs := parseKeyValues(output)
 
 	for _, kv := range kvs {
-		switch kv.key {
-		case "Project Name":
+		switch strings.ToLower(kv.key) {
+		case "project name", "project":
 			result.ProjectName = kv.value
-		case "Repository":
+		case "repository", "repo":
 			result.Repository = kv.value
-		case "Description":
+		case "description":
 			result.Description = kv.value
-		case "Private":
-			result.Private = kv.value == "true"
-		case "Hidden":
-			result.Hidden = kv.value == "true"
-		case "Mirror":
-			result.Mirror = kv.value == "true"
-		case "Owner":
+		case "private":
+			// An unrecognized value counts as not reported, like a missing line
+			result.Private, result.HasPrivate = parseBoolWord(kv.value)
+		case "hidden":
+			result.Hidden = parseBool(kv.value)
+		case "mirror":
+			result.Mirror = parseBool(kv.value)
+		case "mirror url", "mirror remote", "upstream", "upstream url", "remote url":
+			result.MirrorURL = withoutCredentials(kv.value)
+		case "owner":
 			result.Owner = kv.value
+		case "access", "access level":
+			result.Access = kv.value
+		case "default branch":
+			result.DefaultBranch = kv.value
+		case "created at", "created":
+			result.CreatedAt = parseTimestamp(kv.value)
+		case "updated at", "updated":
+			result.UpdatedAt = parseTimestamp(kv.value)
+		case "last push", "last pushed", "pushed at":
+			result.LastPush = parseTimestamp(kv.value)
+		case "size", "disk size", "disk usage", "repository size", "repo size":
+			// Like Private, an unparseable value counts as not reported
+			result.Size, result.HasSize = parseSize(kv.value)
 		}
 	}
 
 	if result.Repository == "" {
-		return nil, fmt.Errorf("failed to parse repo info: missing Repository field")
+		return nil, fmt.Errorf("failed to parse repo info: %w", ErrIncompleteRepoInfo)
 	}
 
+	result.Branches = parseListSection(output, "Branches")
+	result.Tags = parseListSection(output, "Tags")
+
 	return result, nil
 }
 
-// ParseUserInfo parses the output of `user info <username>`.
+// ParseUserInfo parses the output of `user info <username>`. Labels are
+// matched case-insensitively and a few alternative spellings are accepted.
 //
 // Expected format:
 //
@@ -83,21 +141,27 @@ func ParseRepoInfo(output string) (*RepoInfoResult, error) {
 //	Public keys:
 //	  ssh-ed25519 AAAA... alice@host
 //	  ssh-rsa AAAA... alice@other
+//
+// A comment after a key, as some servers print it, is split off into
+// PublicKeyComments. Newer servers may also print "Created At" and
+// "Updated At" timestamps, which are converted to RFC3339.
 func ParseUserInfo(output string) (*UserInfoResult, error) {
 	result := &UserInfoResult{}
-	lines := strings.Split(output, "\n")
-
 	inPublicKeys := false
-	for _, line := range lines {
+	for _, line := range splitLines(output) {
 		if inPublicKeys {
 			trimmed := strings.TrimSpace(line)
 			if trimmed != "" {
-				// Check if this is a new key-value line (not indented content)
-				if !strings.HasPrefix(line, "  ") && strings.Contains(line, ": ") {
+				// An unindented label, with or without a value, starts the
+				// next field; keys are indented and never end in ":".
+				indented := strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")
+				if _, _, ok := parseKeyValue(line); ok && !indented {
 					inPublicKeys = false
 					// Fall through to key-value parsing below
 				} else {
-					result.PublicKeys = append(result.PublicKeys, trimmed)
+					key, comment := SplitPublicKey(trimmed)
+					result.PublicKeys = append(result.PublicKeys, key)
+					result.PublicKeyComments = append(result.PublicKeyComments, comment)
 					continue
 				}
 			} else {
@@ -110,13 +174,17 @@ func ParseUserInfo(output string) (*UserInfoResult, error) {
 			continue
 		}
 
-		switch key {
-		case "Username":
+		switch strings.ToLower(key) {
+		case "username", "user":
 			result.Username = value
-		case "Admin":
-			result.Admin = value == "true"
-		case "Public keys":
+		case "admin", "is admin":
+			result.Admin = parseBool(value)
+		case "public keys", "public key", "keys":
 			inPublicKeys = true
+		case "created at", "created":
+			result.CreatedAt = parseTimestamp(value)
+		case "updated at", "updated":
+			result.UpdatedAt = parseTimestamp(value)
 		}
 	}
 
@@ -127,27 +195,78 @@ func ParseUserInfo(output string) (*UserInfoResult, error) {
 	return result, nil
 }
 
+// ParseIdentityInfo parses the output of `info`, which describes the user the
+// connection authenticated as. Soft Serve versions differ in field names and
+// extra fields, so it matches keys case-insensitively, accepts a few
+// spellings, and ignores anything it doesn't recognize.
+//
+// Typical format:
+//
+//	Username: alice
+//	Admin: false
+//	Public keys:
+//	  ssh-ed25519 AAAA... alice@host
+func ParseIdentityInfo(output string) (*IdentityInfoResult, error) {
+	result := &IdentityInfoResult{}
+	for _, line := range splitLines(output) {
+		// Indented lines belong to a list such as the public keys
+		if strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") {
+			continue
+		}
+		key, value, ok := parseKeyValue(line)
+		if !ok {
+			continue
+		}
+		switch strings.ToLower(key) {
+		case "username", "user":
+			result.Username = value
+		case "admin", "is admin":
+			result.Admin = parseBool(value)
+		case "access", "access level", "role":
+			result.Access = value
+		}
+	}
+
+	if result.Username == "" {
+		return nil, fmt.Errorf("failed to parse info: missing Username field")
+	}
+
+	result.PublicKeys = parseListSection(output, "Public keys")
+
+	return result, nil
+}
+
 // ParseCollabList parses the output of `repo collab list <repo>`.
 //
-// Expected format (one entry per line):
+// Expected format (one entry per line, columns separated by spaces or tabs):
 //
 //	alice read-write
 //	bob read-only
+//
+// Some versions print a tabular listing with a header row, which is skipped:
+//
+//	USERNAME	ACCESS
+//	alice	read-write
+//
+// Entries are sorted by username, since the server's order isn't stable.
 func ParseCollabList(output string) ([]CollabEntry, error) {
 	if strings.TrimSpace(output) == "" {
 		return nil, nil
 	}
 
 	var entries []CollabEntry
-	for _, line := range strings.Split(output, "\n") {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
+	first := true
+	for _, line := range splitLines(output) {
 		parts := strings.Fields(line)
-		if len(parts) == 0 {
+		if len(parts) == 0 || isTableRule(parts) {
 			continue
 		}
+		if first {
+			first = false
+			if isCollabHeader(parts) {
+				continue
+			}
+		}
 		entry := CollabEntry{
 			Username: parts[0],
 		}
@@ -156,9 +275,178 @@ func ParseCollabList(output string) ([]CollabEntry, error) {
 		}
 		entries = append(entries, entry)
 	}
+	slices.SortFunc(entries, func(a, b CollabEntry) int {
+		return cmp.Compare(a.Username, b.Username)
+	})
 	return entries, nil
 }
 
+// isCollabHeader reports whether parts is a column header row such as
+// "USERNAME ACCESS". A real entry's second column is an access level, which
+// tells a user who happens to be called "user" apart from a header.
+func isCollabHeader(parts []string) bool {
+	if len(parts) < 2 {
+		return false
+	}
+	switch strings.ToLower(parts[0]) {
+	case "username", "user", "name", "collaborator":
+		_, isLevel := NormalizeAccessLevel(parts[1])
+		return !isLevel
+	}
+	return false
+}
+
+// isTableRule reports whether parts is a separator row like "-------- ------".
+func isTableRule(parts []string) bool {
+	for _, p := range parts {
+		if strings.Trim(p, "-=+|") != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseAnonAccess extracts the access level from the output of
+// `settings anon-access`. Servers print either the bare level or a sentence
+// or label around it, such as "anon-access: read-only" or "read-only.", so it
+// returns the part of the output that is an access level, in the server's
+// spelling, preferring canonical levels over aliases. Output without one is
+// returned trimmed, for the caller to report.
+func ParseAnonAccess(output string) string {
+	value := strings.TrimSpace(output)
+	if _, v, ok := parseKeyValue(value); ok && v != "" {
+		value = v
+	}
+	if level := trimPunctuation(value); isAccessLevel(level) {
+		return level
+	}
+
+	fields := strings.Fields(value)
+	for i := range fields {
+		fields[i] = trimPunctuation(fields[i])
+	}
+	for _, f := range fields {
+		if AccessLevel(strings.ToLower(f)).Valid() {
+			return f
+		}
+	}
+	for _, f := range fields {
+		if isAccessLevel(f) {
+			return f
+		}
+	}
+	return strings.TrimSpace(output)
+}
+
+// ParseAllowKeyless extracts the boolean from the output of
+// `settings allow-keyless`. Besides a bare true or false, servers may print a
+// label, as in "allow-keyless: true", or words such as yes, enabled or off.
+// Output with no recognizable boolean is an error, rather than being read as
+// false.
+func ParseAllowKeyless(output string) (bool, error) {
+	value := strings.TrimSpace(output)
+	if _, v, ok := parseKeyValue(value); ok && v != "" {
+		value = v
+	}
+	for _, field := range strings.Fields(value) {
+		if b, ok := parseBoolWord(trimPunctuation(field)); ok {
+			return b, nil
+		}
+	}
+	return false, fmt.Errorf("unrecognized allow-keyless value %q", strings.TrimSpace(output))
+}
+
+// parseBoolWord reads the words servers use for boolean settings, in any
+// case. It reports false when s isn't one of them.
+func parseBoolWord(s string) (value, ok bool) {
+	switch strings.ToLower(s) {
+	case "true", "yes", "on", "enabled", "1":
+		return true, true
+	case "false", "no", "off", "disabled", "0":
+		return false, true
+	}
+	return false, false
+}
+
+// isAccessLevel reports whether s is an access level in any spelling that
+// NormalizeAccessLevel accepts.
+func isAccessLevel(s string) bool {
+	_, ok := NormalizeAccessLevel(s)
+	return ok
+}
+
+// trimPunctuation removes quotes and sentence punctuation around s.
+func trimPunctuation(s string) string {
+	return strings.Trim(s, `"'.,;:!()`+"`")
+}
+
+// ParseBranchList parses the output of `repo branch list <repo>`, one branch
+// per line.
+func ParseBranchList(output string) []string {
+	var branches []string
+	for _, line := range splitLines(output) {
+		if branch := strings.TrimSpace(line); branch != "" {
+			branches = append(branches, branch)
+		}
+	}
+	return branches
+}
+
+// ParseRepoList parses the output of `repo list`, one repository per line.
+// Nested repositories keep their full name, e.g. "team/service".
+func ParseRepoList(output string) []string {
+	var repos []string
+	for _, line := range splitLines(output) {
+		if repo := strings.TrimSpace(line); repo != "" {
+			repos = append(repos, repo)
+		}
+	}
+	return repos
+}
+
+// ParseUserList parses the output of `user list`, one username per line.
+// Only the first column is used, so tabular listings work too, and a
+// "USERNAME" header row is skipped.
+func ParseUserList(output string) []string {
+	var users []string
+	for _, line := range splitLines(output) {
+		parts := strings.Fields(line)
+		if len(parts) == 0 || isTableRule(parts) {
+			continue
+		}
+		if len(users) == 0 && len(parts) > 1 && strings.EqualFold(parts[0], "username") {
+			continue
+		}
+		users = append(users, parts[0])
+	}
+	return users
+}
+
+// PublicKeyFingerprint returns the SHA256 fingerprint of an authorized_keys
+// formatted public key, as printed by `ssh-keygen -l`.
+func PublicKeyFingerprint(key string) (string, error) {
+	pub, _, _, _, err := ssh.ParseAuthorizedKey([]byte(key))
+	if err != nil {
+		return "", fmt.Errorf("parsing public key %q: %w", key, err)
+	}
+	return ssh.FingerprintSHA256(pub), nil
+}
+
+// SplitPublicKey splits an authorized_keys formatted public key into the key
+// itself, its type and base64 data, and the comment after them, e.g. a title
+// like "alice@laptop". Either may be empty.
+func SplitPublicKey(line string) (key, comment string) {
+	fields := strings.Fields(line)
+	if len(fields) < 2 {
+		return strings.TrimSpace(line), ""
+	}
+	key = fields[0] + " " + fields[1]
+	rest := strings.TrimSpace(line)
+	rest = strings.TrimSpace(strings.TrimPrefix(rest, fields[0]))
+	rest = strings.TrimSpace(strings.TrimPrefix(rest, fields[1]))
+	return key, rest
+}
+
 type keyValue struct {
 	key   string
 	value string
@@ -166,7 +454,7 @@ type keyValue struct {
 
 func parseKeyValues(output string) []keyValue {
 	var kvs []keyValue
-	for _, line := range strings.Split(output, "\n") {
+	for _, line := range splitLines(output) {
 		key, value, ok := parseKeyValue(line)
 		if ok {
 			kvs = append(kvs, keyValue{key: key, value: value})
@@ -175,6 +463,126 @@ func parseKeyValues(output string) []keyValue {
 	return kvs
 }
 
+// parseListSection returns the indented lines listed under a "heading:"
+// line, with any "- " bullet removed, stopping at the next unindented line.
+// The heading is matched case-insensitively.
+func parseListSection(output, heading string) []string {
+	var items []string
+	inSection := false
+	for _, line := range splitLines(output) {
+		trimmed := strings.TrimSpace(line)
+		if !inSection {
+			inSection = strings.EqualFold(trimmed, heading+":")
+			continue
+		}
+		if trimmed == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") {
+			break
+		}
+		items = append(items, strings.TrimSpace(strings.TrimPrefix(trimmed, "- ")))
+	}
+	return items
+}
+
+// timestampLayouts are the timestamp formats Soft Serve is known to print,
+// including Go's default time.Time formatting.
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999 -0700 MST",
+	"2006-01-02 15:04:05.999999999 -0700",
+	time.DateTime,
+}
+
+// parseTimestamp converts a timestamp in one of timestampLayouts to RFC3339.
+// It returns "" for a value in any other format.
+func parseTimestamp(value string) string {
+	// Drop the monotonic clock reading Go appends, e.g. "m=+0.001"
+	if i := strings.Index(value, " m="); i >= 0 {
+		value = value[:i]
+	}
+	for _, layout := range timestampLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.Format(time.RFC3339)
+		}
+	}
+	return ""
+}
+
+// sizeUnits maps the unit suffixes parseSize accepts, lowercased, to their
+// multiple of a byte. Decimal (kB, MB) and binary (KiB, MiB) units follow
+// their standard meanings; single letters, as du prints them, are binary.
+var sizeUnits = map[string]float64{
+	"":      1,
+	"b":     1,
+	"byte":  1,
+	"bytes": 1,
+	"kb":    1e3,
+	"mb":    1e6,
+	"gb":    1e9,
+	"tb":    1e12,
+	"k":     1 << 10,
+	"kib":   1 << 10,
+	"m":     1 << 20,
+	"mib":   1 << 20,
+	"g":     1 << 30,
+	"gib":   1 << 30,
+	"t":     1 << 40,
+	"tib":   1 << 40,
+}
+
+// parseSize converts a size such as "2048", "2048 bytes", "1.5 MiB" or
+// "12K" to bytes, rounding fractions down. ok is false for a negative value
+// or one in any other format.
+func parseSize(value string) (size int64, ok bool) {
+	value = strings.TrimSpace(value)
+	i := strings.IndexFunc(value, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if i < 0 {
+		i = len(value)
+	}
+	n, err := strconv.ParseFloat(value[:i], 64)
+	if err != nil {
+		return 0, false
+	}
+	unit, known := sizeUnits[strings.ToLower(strings.TrimSpace(value[i:]))]
+	if !known || n*unit >= math.MaxInt64 {
+		return 0, false
+	}
+	return int64(n * unit), true
+}
+
+// withoutCredentials removes any user info from rawURL, since servers may
+// print a mirror's upstream URL with the credentials it was imported with.
+// Values that don't parse as URLs are returned unchanged.
+func withoutCredentials(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.User == nil {
+		return rawURL
+	}
+	u.User = nil
+	return u.String()
+}
+
+// parseBool reports whether a boolean field's value is true, in any of the
+// spellings parseBoolWord accepts. Unrecognized values are false.
+func parseBool(value string) bool {
+	b, _ := parseBoolWord(value)
+	return b
+}
+
+// splitLines splits output into lines, dropping the carriage return of CRLF
+// line endings so it doesn't end up in parsed values.
+func splitLines(output string) []string {
+	lines := strings.Split(output, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	return lines
+}
+
 func parseKeyValue(line string) (string, string, bool) {
 	idx := strings.Index(line, ": ")
 	if idx < 0 {
@@ -0,0 +1,148 @@
+package ssh
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxProxyStderr bounds how much of a proxy command's stderr is kept for
+// error messages.
+const maxProxyStderr = 4096
+
+// proxyWaitDelay bounds how long closing a proxy connection waits for the
+// command's output streams after killing it, in case a child process it
+// started keeps them open.
+const proxyWaitDelay = 2 * time.Second
+
+// expandProxyCommand substitutes the OpenSSH ProxyCommand tokens %h (host),
+// %p (port), %r (username) and %% in command.
+func expandProxyCommand(command, host string, port int, username string) string {
+	return strings.NewReplacer(
+		"%%", "%",
+		"%h", host,
+		"%p", strconv.Itoa(port),
+		"%r", username,
+	).Replace(command)
+}
+
+// startProxyCommand runs command through the shell and returns a connection
+// to addr that reads from its stdout and writes to its stdin, as OpenSSH does
+// for ProxyCommand. The command runs until the connection is closed; it is
+// not tied to a context because the connection outlives the call that dialed
+// it.
+func startProxyCommand(command, addr string) (*proxyConn, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("/bin/sh", "-c", command)
+	}
+	startInProcessGroup(cmd)
+	cmd.WaitDelay = proxyWaitDelay
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("proxy command: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("proxy command: %w", err)
+	}
+	pc := &proxyConn{cmd: cmd, stdin: stdin, stdout: stdout, addr: proxyAddr(addr)}
+	cmd.Stderr = &pc.stderr
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("starting proxy command %q: %w", command, err)
+	}
+	return pc, nil
+}
+
+// proxyConn is a net.Conn over the standard streams of a proxy command.
+// Deadlines are not supported and are silently ignored.
+type proxyConn struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout io.ReadCloser
+	stderr cappedBuffer
+	addr   proxyAddr
+
+	closeOnce sync.Once
+	closeErr  error
+}
+
+func (p *proxyConn) Read(b []byte) (int, error)  { return p.stdout.Read(b) }
+func (p *proxyConn) Write(b []byte) (int, error) { return p.stdin.Write(b) }
+
+// Close closes the command's stdin, which ends well-behaved proxies, and then
+// kills it, along with the processes it started, in case it doesn't exit by
+// itself.
+func (p *proxyConn) Close() error {
+	p.closeOnce.Do(func() {
+		_ = p.stdin.Close()
+		killProcessGroup(p.cmd)
+		var exitErr *exec.ExitError
+		if err := p.cmd.Wait(); err != nil && !errors.As(err, &exitErr) && !errors.Is(err, exec.ErrWaitDelay) {
+			p.closeErr = err
+		}
+	})
+	return p.closeErr
+}
+
+// Stderr returns what the proxy command has written to stderr so far.
+func (p *proxyConn) Stderr() string {
+	return p.stderr.String()
+}
+
+func (p *proxyConn) LocalAddr() net.Addr              { return p.addr }
+func (p *proxyConn) RemoteAddr() net.Addr             { return p.addr }
+func (p *proxyConn) SetDeadline(time.Time) error      { return nil }
+func (p *proxyConn) SetReadDeadline(time.Time) error  { return nil }
+func (p *proxyConn) SetWriteDeadline(time.Time) error { return nil }
+
+// proxyAddr is the server's host:port, standing in for the addresses of a
+// connection that has none of its own, such as a proxy command or Unix
+// socket, so host key checks see the server's address.
+type proxyAddr string
+
+func (proxyAddr) Network() string  { return "proxy" }
+func (a proxyAddr) String() string { return string(a) }
+
+// remoteAddrConn overrides the remote address of a connection that doesn't
+// have a host:port one, such as a Unix socket.
+type remoteAddrConn struct {
+	net.Conn
+	addr net.Addr
+}
+
+func (c remoteAddrConn) RemoteAddr() net.Addr { return c.addr }
+
+// cappedBuffer keeps the first maxProxyStderr bytes written to it. It is safe
+// for concurrent use, since exec copies stderr from its own goroutine.
+type cappedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if room := maxProxyStderr - b.buf.Len(); room > 0 {
+		b.buf.Write(p[:min(len(p), room)])
+	}
+	return len(p), nil
+}
+
+func (b *cappedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return strings.TrimSpace(b.buf.String())
+}
@@ -0,0 +1,16 @@
+//go:build !unix
+
+package ssh
+
+import "os/exec"
+
+// startInProcessGroup does nothing where process groups aren't available;
+// cmd.WaitDelay still bounds how long closing waits for its children.
+func startInProcessGroup(*exec.Cmd) {}
+
+// killProcessGroup kills cmd itself.
+func killProcessGroup(cmd *exec.Cmd) {
+	if cmd.Process != nil {
+		_ = cmd.Process.Kill()
+	}
+}
@@ -0,0 +1,131 @@
+package ssh
+
+import (
+	"context"
+	"flag"
+	"io"
+	"net"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ssoriche/terraform-provider-soft-serve/internal/sshtest"
+)
+
+func TestExpandProxyCommand(t *testing.T) {
+	tests := []struct {
+		command string
+		want    string
+	}{
+		{"cloudflared access ssh --hostname %h", "cloudflared access ssh --hostname git.example.com"},
+		{"nc %h %p", "nc git.example.com 23231"},
+		{"ssh -W %h:%p %r@bastion", "ssh -W git.example.com:23231 admin@bastion"},
+		{"printf 100%%", "printf 100%"},
+		{"plain", "plain"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			if got := expandProxyCommand(tt.command, "git.example.com", 23231, "admin"); got != tt.want {
+				t.Errorf("expandProxyCommand() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestProxyCommandHelper is not a real test: it is the proxy command run by
+// TestRun_ProxyCommand. It relays stdin and stdout to the host and port given
+// as arguments, like `nc %h %p`, and exits when stdin is closed.
+func TestProxyCommandHelper(t *testing.T) {
+	if os.Getenv("SOFT_SERVE_TEST_PROXY_HELPER") != "1" {
+		t.Skip("helper process for TestRun_ProxyCommand")
+	}
+	args := flag.Args()
+	conn, err := net.Dial("tcp", net.JoinHostPort(args[0], args[1]))
+	if err != nil {
+		os.Exit(1)
+	}
+	go func() { _, _ = io.Copy(os.Stdout, conn) }()
+	_, _ = io.Copy(conn, os.Stdin)
+	os.Exit(0)
+}
+
+func TestRun_ProxyCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("proxy command quoting is POSIX shell syntax")
+	}
+	t.Setenv("SOFT_SERVE_TEST_PROXY_HELPER", "1")
+	srv := sshtest.NewServer(t, func(string) (string, error) { return "myrepo", nil })
+	c, err := NewClient(ClientConfig{
+		Host:         srv.Host(),
+		Port:         srv.Port(),
+		Username:     "admin",
+		PrivateKey:   testPrivateKey(t),
+		ProxyCommand: "'" + os.Args[0] + "' -test.run=^TestProxyCommandHelper$ -- %h %p",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+
+	out, err := c.Run(context.Background(), "repo list")
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if out != "myrepo" {
+		t.Errorf("Run() = %q, want %q", out, "myrepo")
+	}
+	if got := srv.Connections(); got != 1 {
+		t.Errorf("server accepted %d connections, want 1", got)
+	}
+}
+
+func TestRun_ProxyCommandFailureIncludesStderr(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("proxy command is POSIX shell syntax")
+	}
+	c, err := NewClient(ClientConfig{
+		Host:         "git.example.com",
+		Port:         23231,
+		Username:     "admin",
+		PrivateKey:   testPrivateKey(t),
+		ProxyCommand: "echo 'tunnel to %h refused' >&2; exit 1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+
+	_, err = c.Run(context.Background(), "repo list")
+	if err == nil {
+		t.Fatal("Run() should fail when the proxy command exits")
+	}
+	if !strings.Contains(err.Error(), "tunnel to git.example.com refused") {
+		t.Errorf("error = %v, want it to include the proxy command's stderr", err)
+	}
+}
+
+func TestProxyConnClose_ChildHoldsPipes(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("proxy command is POSIX shell syntax")
+	}
+	// The backgrounded sleep inherits stdout and stderr and outlives the
+	// shell unless its process group is killed too.
+	pc, err := startProxyCommand("sleep 60 & cat", "git.example.com:23231")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- pc.Close() }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Close() error = %v", err)
+		}
+	case <-time.After(proxyWaitDelay + 5*time.Second):
+		t.Fatal("Close() hung while a child of the proxy command held its pipes open")
+	}
+}
@@ -0,0 +1,24 @@
+//go:build unix
+
+package ssh
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// startInProcessGroup makes cmd lead a process group of its own, so that
+// killProcessGroup reaches the proxy the shell started as well as the shell.
+func startInProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// killProcessGroup kills the process group cmd leads.
+func killProcessGroup(cmd *exec.Cmd) {
+	if cmd.Process == nil {
+		return
+	}
+	if err := syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL); err != nil {
+		_ = cmd.Process.Kill()
+	}
+}
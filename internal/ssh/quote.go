@@ -0,0 +1,19 @@
+package ssh
+
+import "strings"
+
+// quoteArg quotes s for use as a single argument in a command line, so that
+// a name containing spaces or shell metacharacters can't split into several
+// arguments or be interpreted by a shell in a forced-command wrapper. Strings
+// made only of characters that are safe unquoted are returned as they are.
+func quoteArg(s string) string {
+	if s != "" && strings.Trim(s, safeArgChars) == "" {
+		return s
+	}
+	// Single quotes take everything literally; an embedded single quote
+	// closes the string, is escaped, and opens a new one.
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// safeArgChars are the characters quoteArg leaves unquoted.
+const safeArgChars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./@:+=,%"
@@ -0,0 +1,61 @@
+package ssh
+
+import (
+	"context"
+	"os/exec"
+	"runtime"
+	"testing"
+)
+
+func TestQuoteArg(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"myrepo", "myrepo"},
+		{"org/my-repo.v2", "org/my-repo.v2"},
+		{"alice@example.com", "alice@example.com"},
+		{"", "''"},
+		{"foo; rm -rf /", "'foo; rm -rf /'"},
+		{"$(id)", "'$(id)'"},
+		{"it's", `'it'\''s'`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := quoteArg(tt.in); got != tt.want {
+				t.Errorf("quoteArg(%q) = %s, want %s", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestQuoteArg_Shell checks that a shell, as run by a forced-command wrapper,
+// sees a quoted name as exactly one argument with its original value.
+func TestQuoteArg_Shell(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a POSIX shell")
+	}
+	for _, name := range []string{"foo; rm -rf /", "a b", "$(id) `id` $HOME", "it's", `back\slash`, "new\nline"} {
+		out, err := exec.Command("/bin/sh", "-c", `printf '%s|' `+quoteArg(name)).Output()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := string(out); got != name+"|" {
+			t.Errorf("shell saw %q for %q, want a single argument", got, name)
+		}
+	}
+}
+
+func TestRepoCreate_QuotesName(t *testing.T) {
+	c, srv := newTestClient(t, func(string) (string, error) { return "", nil })
+
+	if err := c.RepoCreate(context.Background(), "foo; rm -rf", RepoCreateOpts{}); err != nil {
+		t.Fatalf("RepoCreate() error = %v", err)
+	}
+
+	want := "repo create 'foo; rm -rf' -p=false"
+	if got := srv.Commands(); len(got) != 1 || got[0] != want {
+		t.Errorf("commands = %q, want [%q]", got, want)
+	}
+}
@@ -0,0 +1,319 @@
+// Package sshtest provides an in-process SSH server that stands in for Soft
+// Serve in unit tests.
+package sshtest
+
+import (
+	"bufio"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"io"
+	"maps"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Handler produces the output for a command executed on the test server. A
+// non-nil error is written to stderr and reported as exit status 1.
+type Handler func(command string) (string, error)
+
+// StreamHandler runs a command that converses with the client over stdin and
+// stdout, such as git-upload-pack. A non-nil error is reported as exit status
+// 1.
+type StreamHandler func(command string, stdin io.Reader, stdout, stderr io.Writer) error
+
+// Server is an in-process SSH server that accepts any public key and records
+// every command it executes.
+type Server struct {
+	listener net.Listener
+	config   *ssh.ServerConfig
+	handler  Handler
+	hostKey  ssh.PublicKey
+
+	streams map[string]StreamHandler // by command prefix; guarded by mu
+
+	mu          sync.Mutex
+	commands    []string
+	connections int
+	versions    []string // client identification strings, per connection
+	subsystems  []string
+	banner      string
+	live        map[*ssh.ServerConn]struct{}
+	env         map[string]string   // accepted env requests
+	rejectEnv   map[string]struct{} // env var names to refuse
+}
+
+// NewServer starts a Server on a loopback port. It is shut down when the test
+// finishes.
+func NewServer(t testing.TB, handler Handler) *Server {
+	t.Helper()
+
+	hostKey, err := ssh.ParsePrivateKey([]byte(PrivateKey(t)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(ssh.ConnMetadata, ssh.PublicKey) (*ssh.Permissions, error) {
+			return nil, nil
+		},
+	}
+	config.AddHostKey(hostKey)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("loopback listener unavailable: %v", err)
+	}
+	t.Cleanup(func() { _ = l.Close() })
+
+	s := &Server{listener: l, config: config, handler: handler, hostKey: hostKey.PublicKey()}
+	config.BannerCallback = func(ssh.ConnMetadata) string {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return s.banner
+	}
+	go s.serve()
+	return s
+}
+
+// SetBanner makes the server send banner to clients before authentication,
+// as deployments with an MOTD do. Call it before the first connection.
+func (s *Server) SetBanner(banner string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.banner = banner
+}
+
+// RejectEnv makes the server refuse env requests for the given variable
+// names, as servers whose AcceptEnv doesn't list them do.
+func (s *Server) RejectEnv(names ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.rejectEnv == nil {
+		s.rejectEnv = make(map[string]struct{})
+	}
+	for _, name := range names {
+		s.rejectEnv[name] = struct{}{}
+	}
+}
+
+// HandleStream makes the server run commands starting with prefix with h
+// instead of the Handler.
+func (s *Server) HandleStream(prefix string, h StreamHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.streams == nil {
+		s.streams = make(map[string]StreamHandler)
+	}
+	s.streams[prefix] = h
+}
+
+// Env returns the environment variables set by accepted env requests so far.
+func (s *Server) Env() map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return maps.Clone(s.env)
+}
+
+// HostKey returns the server's public host key.
+func (s *Server) HostKey() ssh.PublicKey {
+	return s.hostKey
+}
+
+// Host returns the address the server is listening on.
+func (s *Server) Host() string {
+	host, _, _ := net.SplitHostPort(s.listener.Addr().String())
+	return host
+}
+
+// Port returns the port the server is listening on.
+func (s *Server) Port() int {
+	_, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	p, _ := strconv.Atoi(port)
+	return p
+}
+
+// Commands returns the commands executed so far, in order.
+func (s *Server) Commands() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.commands...)
+}
+
+// Subsystems returns the names of the subsystems requested so far, in order.
+func (s *Server) Subsystems() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.subsystems...)
+}
+
+// Connections returns the number of SSH connections accepted so far.
+func (s *Server) Connections() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.connections
+}
+
+// ClientVersions returns the identification string each client sent, in the
+// order the connections were accepted.
+func (s *Server) ClientVersions() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.versions...)
+}
+
+// DropConnections closes every open connection, as a server restart would,
+// while continuing to accept new ones.
+func (s *Server) DropConnections() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for conn := range s.live {
+		_ = conn.Close()
+	}
+}
+
+func (s *Server) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handleConn(conn)
+	}
+}
+
+func (s *Server) handleConn(nc net.Conn) {
+	defer func() { _ = nc.Close() }()
+
+	conn, chans, reqs, err := ssh.NewServerConn(nc, s.config)
+	if err != nil {
+		return
+	}
+	defer func() { _ = conn.Close() }()
+	go ssh.DiscardRequests(reqs)
+
+	s.mu.Lock()
+	s.connections++
+	s.versions = append(s.versions, string(conn.ClientVersion()))
+	if s.live == nil {
+		s.live = make(map[*ssh.ServerConn]struct{})
+	}
+	s.live[conn] = struct{}{}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.live, conn)
+		s.mu.Unlock()
+	}()
+
+	for newCh := range chans {
+		if newCh.ChannelType() != "session" {
+			_ = newCh.Reject(ssh.UnknownChannelType, "unsupported channel type")
+			continue
+		}
+		ch, chReqs, err := newCh.Accept()
+		if err != nil {
+			continue
+		}
+		go s.handleSession(ch, chReqs)
+	}
+}
+
+func (s *Server) handleSession(ch ssh.Channel, reqs <-chan *ssh.Request) {
+	defer func() { _ = ch.Close() }()
+
+	for req := range reqs {
+		var command string
+		switch req.Type {
+		case "exec":
+			var payload struct{ Command string }
+			if err := ssh.Unmarshal(req.Payload, &payload); err != nil {
+				_ = req.Reply(false, nil)
+				return
+			}
+			_ = req.Reply(true, nil)
+			command = payload.Command
+		case "env":
+			var payload struct{ Name, Value string }
+			if err := ssh.Unmarshal(req.Payload, &payload); err != nil {
+				_ = req.Reply(false, nil)
+				return
+			}
+			s.mu.Lock()
+			_, rejected := s.rejectEnv[payload.Name]
+			if !rejected {
+				if s.env == nil {
+					s.env = make(map[string]string)
+				}
+				s.env[payload.Name] = payload.Value
+			}
+			s.mu.Unlock()
+			if req.WantReply {
+				_ = req.Reply(!rejected, nil)
+			}
+			continue
+		case "subsystem":
+			// A subsystem reads its command as a single line from stdin.
+			var payload struct{ Name string }
+			if err := ssh.Unmarshal(req.Payload, &payload); err != nil {
+				_ = req.Reply(false, nil)
+				return
+			}
+			_ = req.Reply(true, nil)
+			s.mu.Lock()
+			s.subsystems = append(s.subsystems, payload.Name)
+			s.mu.Unlock()
+			line, _ := bufio.NewReader(ch).ReadString('\n')
+			command = strings.TrimSuffix(line, "\n")
+		default:
+			if req.WantReply {
+				_ = req.Reply(false, nil)
+			}
+			continue
+		}
+
+		s.mu.Lock()
+		s.commands = append(s.commands, command)
+		var stream StreamHandler
+		for prefix, h := range s.streams {
+			if strings.HasPrefix(command, prefix) {
+				stream = h
+			}
+		}
+		s.mu.Unlock()
+
+		var status uint32
+		var err error
+		if stream != nil {
+			err = stream(command, ch, ch, ch.Stderr())
+		} else {
+			var out string
+			out, err = s.handler(command)
+			_, _ = io.WriteString(ch, out)
+		}
+		if err != nil {
+			_, _ = io.WriteString(ch.Stderr(), err.Error())
+			status = 1
+		}
+		_, _ = ch.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{status}))
+		return
+	}
+}
+
+// PrivateKey returns a freshly generated PEM-encoded ed25519 private key.
+func PrivateKey(t testing.TB) string {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := ssh.MarshalPrivateKey(priv, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(block))
+}